@@ -1,6 +1,106 @@
 package binding
 
+import "net/http"
+
 //最常见的数据格式的Content-Type MIME // Content-Type MIME of the most common data formats
 const (
-	MIMEPlain = "text/plain"
+	MIMEJSON              = "application/json"
+	MIMEHTML              = "text/html"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEPlain             = "text/plain"
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+	MIMEYAML              = "application/x-yaml"
+)
+
+// Binding描述了需要实现的接口，以便绑定请求中存在的数据，例如JSON请求正文，查询参数或表单POST。
+// Binding describes the interface which needs to be implemented for binding the
+// data present in the request such as JSON request body, query parameters or
+// the form POST.
+type Binding interface {
+	Name() string
+	Bind(*http.Request, interface{}) error
+}
+
+// BindingBody添加BindBody方法到Binding。 BindBody与Bind相似，但是它从提供的字节而不是req.Body读取正文。
+// BindingBody adds BindBody method to Binding. BindBody is similar with Bind,
+// but it reads the body from supplied bytes instead of req.Body.
+type BindingBody interface {
+	Binding
+	BindBody([]byte, interface{}) error
+}
+
+// BindingUri添加BindUri方法到Binding。 BindUri与Bind相似，但是它从路由参数而不是请求中读取。
+// BindingUri adds BindUri method to Binding. BindUri is similar with Bind,
+// but it read the Params.
+type BindingUri interface {
+	Name() string
+	BindUri(map[string][]string, interface{}) error
+}
+
+// StructValidator是需要实现的最小接口，以便将其用作验证器引擎，以确保请求的正确性。
+// StructValidator is the minimal interface which needs to be implemented in
+// order for it to be used as the validator engine for ensuring the correctness
+// of the request.
+type StructValidator interface {
+	// ValidateStruct可以接收任何类型的类型，即使配置不正确也永远不会panic。
+	// ValidateStruct can receive any kind of type and it should never panic, even if the configuration is not right.
+	// 如果接收到的类型不是结构体，则应跳过任何验证，并且必须返回nil。
+	// If the received type is not a struct, any validation should be skipped and nil must be returned.
+	// 如果接收到的类型是结构体或指向结构体的指针，则应执行验证。
+	// If the received type is a struct or pointer to a struct, the validation should be performed.
+	// 如果结构体无效或验证本身失败，则应返回描述性错误。
+	// If the struct is not valid or the validation itself fails, a descriptive error should be returned.
+	// 否则必须返回nil。 Otherwise nil must be returned.
+	ValidateStruct(interface{}) error
+
+	// Engine返回支持StructValidator实现的基础验证器引擎。 Engine returns the underlying validator engine which powers the StructValidator implementation.
+	Engine() interface{}
+}
+
+// Validator是实现StructValidator接口的默认验证器，为nil时跳过验证。
+// Validator is the default validator which implements the StructValidator interface,
+// validation is skipped while it is nil.
+var Validator StructValidator
+
+// 这些实现了Binding接口，可用于将请求中存在的数据绑定到结构实例。
+// These implement the Binding interface and can be used to bind the data
+// present in the request to struct instances.
+var (
+	JSON          = jsonBinding{}
+	XML           = xmlBinding{}
+	Form          = formBinding{}
+	Query         = queryBinding{}
+	FormPost      = formPostBinding{}
+	FormMultipart = formMultipartBinding{}
+	Uri           = uriBinding{}
+	Header        = headerBinding{}
 )
+
+// Default根据HTTP方法和内容类型返回适当的Binding实例。
+// Default returns the appropriate Binding instance based on the HTTP method
+// and the content type.
+func Default(method, contentType string) Binding {
+	if method == http.MethodGet {
+		return Form
+	}
+
+	switch contentType {
+	case MIMEJSON:
+		return JSON
+	case MIMEXML, MIMEXML2:
+		return XML
+	case MIMEMultipartPOSTForm:
+		return FormMultipart
+	default: // case MIMEPOSTForm:
+		return Form
+	}
+}
+
+func validate(obj interface{}) error {
+	if Validator == nil {
+		return nil
+	}
+	return Validator.ValidateStruct(obj)
+}
@@ -0,0 +1,131 @@
+package binding
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bindTarget struct {
+	Name string `json:"name" xml:"name" form:"name" header:"X-Name" uri:"name"`
+	Age  int    `json:"age" xml:"age" form:"age" header:"X-Age" uri:"age"`
+}
+
+func newMultipartRequest(t *testing.T, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range fields {
+		w.WriteField(k, v)
+	}
+	w.Close()
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestBindings(t *testing.T) {
+	request := func(method, target, contentType, body string) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		return req
+	}
+	headers := request("GET", "/", "", "")
+	headers.Header.Set("X-Name", "gin")
+	headers.Header.Set("X-Age", "3")
+	badHeader := request("GET", "/", "", "")
+	badHeader.Header.Set("X-Age", "three")
+
+	tests := []struct {
+		name    string
+		binding Binding
+		req     *http.Request
+		want    bindTarget
+		wantErr bool
+	}{
+		{"json", JSON, request("POST", "/", MIMEJSON, `{"name":"gin","age":3}`), bindTarget{"gin", 3}, false},
+		{"json invalid", JSON, request("POST", "/", MIMEJSON, `{"name":`), bindTarget{}, true},
+		{"json wrong type", JSON, request("POST", "/", MIMEJSON, `{"age":"x"}`), bindTarget{}, true},
+		{"xml", XML, request("POST", "/", MIMEXML, `<bindTarget><name>gin</name><age>3</age></bindTarget>`), bindTarget{"gin", 3}, false},
+		{"xml invalid", XML, request("POST", "/", MIMEXML, `<bindTarget><name>`), bindTarget{}, true},
+		{"form query", Form, request("GET", "/?name=gin&age=3", "", ""), bindTarget{"gin", 3}, false},
+		{"form body", Form, request("POST", "/?age=3", MIMEPOSTForm, "name=gin"), bindTarget{"gin", 3}, false},
+		{"form invalid", Form, request("GET", "/?age=three", "", ""), bindTarget{}, true},
+		{"form post ignores query", FormPost, request("POST", "/?age=3", MIMEPOSTForm, "name=gin"), bindTarget{Name: "gin"}, false},
+		{"multipart", FormMultipart, newMultipartRequest(t, map[string]string{"name": "gin", "age": "3"}), bindTarget{"gin", 3}, false},
+		{"multipart not multipart", FormMultipart, request("POST", "/", MIMEPOSTForm, "name=gin"), bindTarget{}, true},
+		{"query", Query, request("POST", "/?name=gin&age=3", MIMEPOSTForm, "name=body"), bindTarget{"gin", 3}, false},
+		{"query invalid", Query, request("GET", "/?age=three", "", ""), bindTarget{}, true},
+		{"header", Header, headers, bindTarget{"gin", 3}, false},
+		{"header invalid", Header, badHeader, bindTarget{}, true},
+	}
+	for _, tt := range tests {
+		var got bindTarget
+		err := tt.binding.Bind(tt.req, &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: %s.Bind() error = %v, want error %v", tt.name, tt.binding.Name(), err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%s: %s.Bind() = %+v, want %+v", tt.name, tt.binding.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestBindBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		binding BindingBody
+		body    string
+	}{
+		{"json", JSON, `{"name":"gin","age":3}`},
+		{"xml", XML, `<bindTarget><name>gin</name><age>3</age></bindTarget>`},
+	}
+	for _, tt := range tests {
+		var got bindTarget
+		if err := tt.binding.BindBody([]byte(tt.body), &got); err != nil || got != (bindTarget{"gin", 3}) {
+			t.Errorf("%s: BindBody() = %+v, %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestBindUri(t *testing.T) {
+	var got bindTarget
+	if err := Uri.BindUri(map[string][]string{"name": {"gin"}, "age": {"3"}}, &got); err != nil || got != (bindTarget{"gin", 3}) {
+		t.Errorf("BindUri() = %+v, %v", got, err)
+	}
+	if err := Uri.BindUri(map[string][]string{"age": {"three"}}, &got); err == nil {
+		t.Error("BindUri() accepted an invalid int")
+	}
+	if Uri.Name() != "uri" {
+		t.Errorf("Uri.Name() = %q", Uri.Name())
+	}
+}
+
+func TestDefault(t *testing.T) {
+	tests := []struct {
+		method      string
+		contentType string
+		want        Binding
+	}{
+		{"GET", MIMEJSON, Form},
+		{"GET", "", Form},
+		{"POST", MIMEJSON, JSON},
+		{"PUT", MIMEXML, XML},
+		{"POST", MIMEXML2, XML},
+		{"POST", MIMEPOSTForm, Form},
+		{"POST", MIMEMultipartPOSTForm, FormMultipart},
+		{"POST", MIMEPlain, Form},
+		{"DELETE", "", Form},
+	}
+	for _, tt := range tests {
+		if got := Default(tt.method, tt.contentType); got != tt.want {
+			t.Errorf("Default(%q, %q) = %s, want %s", tt.method, tt.contentType, got.Name(), tt.want.Name())
+		}
+	}
+}
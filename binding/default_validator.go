@@ -0,0 +1,445 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// RuleFunc 报告字段值是否满足带参数的规则，例如 min=3 中的 "3"。 RuleFunc reports whether a field value satisfies a rule with the given param, e.g. "3" for min=3.
+// 指针在调用前已被解引用。 Pointers are dereferenced before it is called.
+type RuleFunc func(field reflect.Value, param string) bool
+
+// FieldError 描述单个字段的验证失败。 FieldError describes the validation failure of a single field.
+type FieldError struct {
+	// Field 是字段的路径，例如 "Items[0].Name"  Field is the path of the field, e.g. "Items[0].Name"
+	Field string `json:"field"`
+	// Rule 是失败的规则名称，例如 "min"  Rule is the name of the failed rule, e.g. "min"
+	Rule string `json:"rule"`
+	// Param 是规则的参数，例如 "3"  Param is the param of the rule, e.g. "3"
+	Param string `json:"param,omitempty"`
+}
+
+// Error实现错误接口。 Error implements the error interface.
+func (e FieldError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("字段 %s 的验证在规则 '%s' 上失败 field validation for '%s' failed on the '%s' rule", e.Field, e.Rule, e.Field, e.Rule)
+	}
+	return fmt.Sprintf("字段 %s 的验证在规则 '%s=%s' 上失败 field validation for '%s' failed on the '%s=%s' rule", e.Field, e.Rule, e.Param, e.Field, e.Rule, e.Param)
+}
+
+// ValidationErrors 是验证失败的所有字段的列表，可以直接编码为JSON。 ValidationErrors is the list of every field failing validation, it can be encoded as JSON directly.
+type ValidationErrors []FieldError
+
+// Error实现错误接口。 Error implements the error interface.
+func (ve ValidationErrors) Error() string {
+	var buf strings.Builder
+	for i, e := range ve {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(e.Error())
+	}
+	return buf.String()
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]RuleFunc{
+		"required": hasValue,
+		"min":      isMin,
+		"max":      isMax,
+		"len":      isLen,
+		"oneof":    isOneOf,
+		"email":    isEmail,
+		"url":      isURL,
+		"alpha":    matchString(regexp.MustCompile(`^[a-zA-Z]+$`)),
+		"alphanum": matchString(regexp.MustCompile(`^[a-zA-Z0-9]+$`)),
+		"numeric":  matchString(regexp.MustCompile(`^[-+]?[0-9]+(?:\.[0-9]+)?$`)),
+	}
+)
+
+// RegisterRule 注册一个自定义规则，以便在binding标签中使用。 RegisterRule registers a custom rule so that it can be used in binding tags:
+//
+//	binding.RegisterRule("even", func(v reflect.Value, _ string) bool { return v.Int()%2 == 0 })
+//	type Req struct { N int `binding:"required,even"` }
+//
+// 它应该在初始化时调用，即在验证使用它的结构体之前。 It should be called at initialization, ie. before any struct using it is validated.
+func RegisterRule(name string, fn RuleFunc) {
+	if name == "" || fn == nil {
+		panic("规则名称和函数不能为空 rule name and func can not be empty")
+	}
+	rulesMu.Lock()
+	rules[name] = fn
+	rulesMu.Unlock()
+}
+
+func lookupRule(name string) (RuleFunc, bool) {
+	rulesMu.RLock()
+	fn, ok := rules[name]
+	rulesMu.RUnlock()
+	return fn, ok
+}
+
+// fieldRule 是从binding标签中解析出的一条规则 fieldRule is one rule parsed from a binding tag.
+type fieldRule struct {
+	name  string
+	param string
+	fn    RuleFunc
+}
+
+// fieldRules 是结构体字段的已解析标签 fieldRules holds the parsed tag of a struct field.
+type fieldRules struct {
+	index     int
+	name      string
+	omitempty bool
+	rules     []fieldRule
+	// dive 之后的规则应用于切片、数组或映射的每个元素 the rules after dive apply to every element of a slice, array or map
+	dive []fieldRule
+}
+
+// parsedStruct 是结构体类型已缓存的解析结果，标签无效时err非nil parsedStruct is the cached parse result of a struct type, err is set if a tag is invalid.
+type parsedStruct struct {
+	fields []fieldRules
+	err    error
+}
+
+type defaultValidator struct {
+	tagName string
+	cache   sync.Map // reflect.Type -> *parsedStruct
+}
+
+var defaultValidatorInstance = &defaultValidator{tagName: "binding"}
+
+func init() {
+	Validator = defaultValidatorInstance
+}
+
+var _ StructValidator = &defaultValidator{}
+
+// ValidateStruct 按binding标签验证结构体、指向结构体的指针或它们的切片。 ValidateStruct validates a struct, a pointer to a struct or a slice of them by their binding tags.
+// 其他类型被跳过。失败时返回 ValidationErrors。 Other types are skipped. ValidationErrors is returned on failure.
+// 未定义的规则或无效的规则参数作为普通错误返回，而不是panic。 An undefined rule or an invalid rule param is returned as a plain error instead of a panic.
+func (v *defaultValidator) ValidateStruct(obj interface{}) error {
+	if obj == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if err := v.validateValue(reflect.ValueOf(obj), "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Engine返回验证器本身。 Engine returns the validator itself.
+func (v *defaultValidator) Engine() interface{} {
+	return v
+}
+
+// validateValue 遍历嵌套的结构体、切片和映射，收集所有字段错误。 validateValue walks nested structs, slices and maps collecting every field error.
+// 只有当遇到的结构体类型的标签无效时才返回错误。 An error is only returned if the tags of a struct type met on the way are invalid.
+func (v *defaultValidator) validateValue(value reflect.Value, path string, errs *ValidationErrors) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		parsed := v.parse(value.Type())
+		if parsed.err != nil {
+			return parsed.err
+		}
+		for _, fr := range parsed.fields {
+			if err := v.validateField(value.Field(fr.index), joinPath(path, fr.name), fr, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.validateValue(value.Index(i), path+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := v.validateValue(iter.Value(), path+"["+fmt.Sprint(iter.Key().Interface())+"]", errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *defaultValidator) validateField(field reflect.Value, path string, fr fieldRules, errs *ValidationErrors) error {
+	if fr.omitempty && !hasValue(field, "") {
+		return nil
+	}
+	if !applyRules(field, path, fr.rules, errs) {
+		return nil
+	}
+
+	if len(fr.dive) > 0 {
+		elem := indirect(field)
+		switch elem.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < elem.Len(); i++ {
+				applyRules(elem.Index(i), path+"["+strconv.Itoa(i)+"]", fr.dive, errs)
+			}
+		case reflect.Map:
+			iter := elem.MapRange()
+			for iter.Next() {
+				applyRules(iter.Value(), path+"["+fmt.Sprint(iter.Key().Interface())+"]", fr.dive, errs)
+			}
+		}
+	}
+
+	return v.validateValue(field, path, errs)
+}
+
+// applyRules 应用规则，并在第一个失败的规则处停止。 applyRules applies the rules, stopping at the first failing one.
+// required 检查字段本身，其他规则检查解引用后的值，并跳过nil指针。 required checks the field itself, the other rules check the dereferenced value and skip nil pointers.
+func applyRules(field reflect.Value, path string, rs []fieldRule, errs *ValidationErrors) bool {
+	value := indirect(field)
+	for _, r := range rs {
+		target := value
+		if r.name == "required" {
+			target = field
+		} else if value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			continue
+		}
+		if !r.fn(target, r.param) {
+			*errs = append(*errs, FieldError{Field: path, Rule: r.name, Param: r.param})
+			return false
+		}
+	}
+	return true
+}
+
+// parse 解析并缓存结构体类型的binding标签。 parse parses and caches the binding tags of a struct type.
+// 规则名称和参数在首次缓存类型时被检查，因此无效的标签对该类型的每次验证都报告相同的错误。
+// Rule names and params are checked when the type is first cached, so an invalid tag reports the same error on every validation of the type.
+// 嵌套的结构体类型也会被检查，即使它们的值当前为nil。 Nested struct types are checked as well, even if their values are currently nil.
+func (v *defaultValidator) parse(t reflect.Type) *parsedStruct {
+	if cached, ok := v.cache.Load(t); ok {
+		return cached.(*parsedStruct)
+	}
+	frs, err := v.parseFields(t, map[reflect.Type]bool{t: true})
+	parsed := &parsedStruct{fields: frs, err: err}
+	v.cache.Store(t, parsed)
+	return parsed
+}
+
+// parseFields 解析t的字段标签，并检查尚未在seen中的嵌套结构体类型。 parseFields parses the field tags of t
+// and checks the nested struct types not yet in seen.
+func (v *defaultValidator) parseFields(t reflect.Type, seen map[reflect.Type]bool) ([]fieldRules, error) {
+	var frs []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous { // 未导出 unexported
+			continue
+		}
+		tag := sf.Tag.Get(v.tagName)
+		if tag == "-" {
+			continue
+		}
+		fr := fieldRules{index: i, name: sf.Name}
+		if sf.Anonymous {
+			fr.name = ""
+		}
+		target := &fr.rules
+		for _, part := range strings.Split(tag, ",") {
+			name, param := part, ""
+			if j := strings.IndexByte(part, '='); j >= 0 {
+				name, param = part[:j], part[j+1:]
+			}
+			switch name {
+			case "":
+				continue
+			case "omitempty":
+				fr.omitempty = true
+				continue
+			case "dive":
+				target = &fr.dive
+				continue
+			}
+			fn, ok := lookupRule(name)
+			if !ok {
+				return nil, fmt.Errorf("未定义的验证规则 undefined validation rule '%s' on field '%s' of %s", name, sf.Name, t)
+			}
+			if check, ok := ruleParams[name]; ok {
+				if err := check(param); err != nil {
+					return nil, fmt.Errorf("无效的规则参数 invalid param for rule '%s' on field '%s' of %s: %v", name, sf.Name, t, err)
+				}
+			}
+			*target = append(*target, fieldRule{name: name, param: param, fn: fn})
+		}
+		frs = append(frs, fr)
+
+		if nested := structElem(sf.Type); nested != nil && !seen[nested] {
+			seen[nested] = true
+			if _, err := v.parseFields(nested, seen); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return frs, nil
+}
+
+// structElem 返回指针、切片、数组或映射最终指向的结构体类型，如果没有则返回nil。 structElem returns the struct type a pointer, slice,
+// array or map eventually holds, or nil if there is none.
+func structElem(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	switch {
+	case path == "":
+		return name
+	case name == "":
+		return path
+	default:
+		return path + "." + name
+	}
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// hasValue 报告值是否不是其类型的零值。 hasValue reports whether the value is not the zero value of its type.
+func hasValue(field reflect.Value, _ string) bool {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Chan:
+		return !field.IsNil() && field.Len() > 0
+	case reflect.Ptr, reflect.Interface, reflect.Func:
+		return !field.IsNil()
+	case reflect.Invalid:
+		return false
+	default:
+		return !field.IsZero()
+	}
+}
+
+// size 返回用于min、max和len比较的值：字符串的字符数、集合的长度或数字本身。
+// size returns the value compared by min, max and len: the rune count of strings, the length of collections or the number itself.
+func size(field reflect.Value) (float64, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(field.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(field.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(field.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), true
+	}
+	return 0, false
+}
+
+// ruleParams 检查内置规则的参数，在解析标签时调用。 ruleParams checks the params of the built-in rules, it is called when tags are parsed.
+var ruleParams = map[string]func(param string) error{
+	"min": numberParam,
+	"max": numberParam,
+	"len": numberParam,
+	"oneof": func(param string) error {
+		if strings.TrimSpace(param) == "" {
+			return errors.New("需要至少一个选项 needs at least one option")
+		}
+		return nil
+	},
+}
+
+func numberParam(param string) error {
+	if _, err := strconv.ParseFloat(param, 64); err != nil {
+		return fmt.Errorf("%q 不是数字 is not a number", param)
+	}
+	return nil
+}
+
+func compareSize(field reflect.Value, param string, ok func(n, p float64) bool) bool {
+	n, valid := size(field)
+	if !valid {
+		return false
+	}
+	p, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	return ok(n, p)
+}
+
+func isMin(field reflect.Value, param string) bool {
+	return compareSize(field, param, func(n, p float64) bool { return n >= p })
+}
+
+func isMax(field reflect.Value, param string) bool {
+	return compareSize(field, param, func(n, p float64) bool { return n <= p })
+}
+
+func isLen(field reflect.Value, param string) bool {
+	return compareSize(field, param, func(n, p float64) bool { return n == p })
+}
+
+// isOneOf 检查值是否是以空格分隔的参数之一，例如 oneof=red green blue  isOneOf checks the value is one of the space separated params, e.g. oneof=red green blue
+func isOneOf(field reflect.Value, param string) bool {
+	if !field.IsValid() {
+		return false
+	}
+	value := fmt.Sprint(field.Interface())
+	for _, option := range strings.Fields(param) {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+func isEmail(field reflect.Value, _ string) bool {
+	return field.Kind() == reflect.String && emailRegex.MatchString(field.String())
+}
+
+func isURL(field reflect.Value, _ string) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	u, err := url.ParseRequestURI(field.String())
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func matchString(re *regexp.Regexp) RuleFunc {
+	return func(field reflect.Value, _ string) bool {
+		return field.Kind() == reflect.String && re.MatchString(field.String())
+	}
+}
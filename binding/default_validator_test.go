@@ -0,0 +1,151 @@
+package binding
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	City string `binding:"required"`
+	Zip  string `binding:"omitempty,len=5,numeric"`
+}
+
+type testUser struct {
+	Name    string            `binding:"required,min=2,max=10"`
+	Email   string            `binding:"omitempty,email"`
+	Age     int               `binding:"min=0,max=130"`
+	Color   string            `binding:"omitempty,oneof=red green blue"`
+	Site    *string           `binding:"omitempty,url"`
+	Tags    []string          `binding:"max=3,dive,alpha"`
+	Address *testAddress      `binding:"required"`
+	Extra   map[string]string `binding:"dive,required"`
+	Skipped string            `binding:"-"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	site := "not a url"
+	valid := func() testUser {
+		return testUser{Name: "gin", Age: 3, Tags: []string{"go"}, Address: &testAddress{City: "x"}}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(u *testUser)
+		want   ValidationErrors
+	}{
+		{"valid", func(u *testUser) {}, nil},
+		{"required", func(u *testUser) { u.Name = "" }, ValidationErrors{{Field: "Name", Rule: "required"}}},
+		{"min", func(u *testUser) { u.Name = "g" }, ValidationErrors{{Field: "Name", Rule: "min", Param: "2"}}},
+		{"max runes", func(u *testUser) { u.Name = "ginginginγγ" }, ValidationErrors{{Field: "Name", Rule: "max", Param: "10"}}},
+		{"email", func(u *testUser) { u.Email = "nope" }, ValidationErrors{{Field: "Email", Rule: "email"}}},
+		{"oneof", func(u *testUser) { u.Color = "pink" }, ValidationErrors{{Field: "Color", Rule: "oneof", Param: "red green blue"}}},
+		{"pointer url", func(u *testUser) { u.Site = &site }, ValidationErrors{{Field: "Site", Rule: "url"}}},
+		{"dive", func(u *testUser) { u.Tags = []string{"go", "1"} }, ValidationErrors{{Field: "Tags[1]", Rule: "alpha"}}},
+		{"max len", func(u *testUser) { u.Tags = []string{"a", "b", "c", "d"} }, ValidationErrors{{Field: "Tags", Rule: "max", Param: "3"}}},
+		{"nested", func(u *testUser) { u.Address.City = "" }, ValidationErrors{{Field: "Address.City", Rule: "required"}}},
+		{"nested len", func(u *testUser) { u.Address.Zip = "123" }, ValidationErrors{{Field: "Address.Zip", Rule: "len", Param: "5"}}},
+		{"nil pointer", func(u *testUser) { u.Address = nil }, ValidationErrors{{Field: "Address", Rule: "required"}}},
+		{"map dive", func(u *testUser) { u.Extra = map[string]string{"k": ""} }, ValidationErrors{{Field: "Extra[k]", Rule: "required"}}},
+		{"skipped", func(u *testUser) { u.Skipped = "" }, nil},
+		{"several", func(u *testUser) { u.Name, u.Age = "", -1 }, ValidationErrors{
+			{Field: "Name", Rule: "required"},
+			{Field: "Age", Rule: "min", Param: "0"},
+		}},
+	}
+	for _, tt := range tests {
+		u := valid()
+		tt.modify(&u)
+		err := Validator.ValidateStruct(&u)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		if got, ok := err.(ValidationErrors); !ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestValidateStructSlice(t *testing.T) {
+	err := Validator.ValidateStruct([]testAddress{{City: "a"}, {}})
+	want := ValidationErrors{{Field: "[1].City", Rule: "required"}}
+	if got, ok := err.(ValidationErrors); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", err, want)
+	}
+	if err := Validator.ValidateStruct(42); err != nil {
+		t.Errorf("non-struct: unexpected error %v", err)
+	}
+}
+
+type badRule struct {
+	A string `binding:"required,nosuchrule"`
+}
+
+type badMin struct {
+	A string `binding:"min=abc"`
+}
+
+type badLen struct {
+	A []int `binding:"dive,len=x"`
+}
+
+type badOneOf struct {
+	A string `binding:"oneof="`
+}
+
+type badNested struct {
+	Inner *badMin
+}
+
+func TestValidateStructInvalidTags(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+		want string
+	}{
+		{"undefined rule", &badRule{A: "x"}, "undefined validation rule 'nosuchrule'"},
+		{"non-numeric min", badMin{A: "x"}, "invalid param for rule 'min'"},
+		{"non-numeric len after dive", badLen{A: []int{1}}, "invalid param for rule 'len'"},
+		{"empty oneof", badOneOf{}, "invalid param for rule 'oneof'"},
+		{"nil nested struct", badNested{}, "invalid param for rule 'min' on field 'A' of binding.badMin"},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 2; i++ { // 第二次来自缓存 the second run hits the cache
+			var err error
+			recv := func() (recv interface{}) {
+				defer func() { recv = recover() }()
+				err = Validator.ValidateStruct(tt.obj)
+				return
+			}()
+			if recv != nil {
+				t.Fatalf("%s: ValidateStruct panicked: %v", tt.name, recv)
+			}
+			if _, ok := err.(ValidationErrors); ok || err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("%s: got error %v, want one containing %q", tt.name, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	RegisterRule("even", func(v reflect.Value, _ string) bool { return v.Int()%2 == 0 })
+	defer func() {
+		rulesMu.Lock()
+		delete(rules, "even")
+		rulesMu.Unlock()
+	}()
+
+	type req struct {
+		N int `binding:"even"`
+	}
+	if err := Validator.ValidateStruct(req{N: 2}); err != nil {
+		t.Errorf("even 2: unexpected error %v", err)
+	}
+	want := ValidationErrors{{Field: "N", Rule: "even"}}
+	if err := Validator.ValidateStruct(req{N: 3}); !reflect.DeepEqual(err, want) {
+		t.Errorf("even 3: got %#v, want %#v", err, want)
+	}
+}
@@ -1,19 +1,32 @@
 package gin_web
 
 import (
+	"context"
+	"errors"
 	"github.com/sourcecmdb/gin-web/binding"
 	"github.com/sourcecmdb/gin-web/render"
 	"math"
 	"net"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 //最常见的数据格式的Content-Type MIME // Content-Type MIME of the most common data formats
 const (
-	MIMEPlain = binding.MIMEPlain
+	MIMEJSON              = binding.MIMEJSON
+	MIMEHTML              = binding.MIMEHTML
+	MIMEXML               = binding.MIMEXML
+	MIMEXML2              = binding.MIMEXML2
+	MIMEPlain             = binding.MIMEPlain
+	MIMEPOSTForm          = binding.MIMEPOSTForm
+	MIMEMultipartPOSTForm = binding.MIMEMultipartPOSTForm
+	MIMEYAML              = binding.MIMEYAML
 )
 
 const abortIndex int8 = math.MaxInt8 / 2
@@ -54,12 +67,21 @@ type Context struct {
 	// SameSite允许服务器定义cookie属性，从而无法   SameSite allows a server to define a cookie attribute making it impossible for
 	// 浏览器将此Cookie与跨站点请求一起发送。  the browser to send this cookie along with cross-site requests.
 	sameSite http.SameSite
+
+	// generation 在reset和release时各递增一次，请求处理期间为奇数，在池中时为偶数。 generation is incremented by both reset and release,
+	// it is odd while a request is served and even while the context sits in the pool, 0 means it never served one.
+	generation uint32
 }
 
+var _ context.Context = &Context{}
+
+// requestHeader 返回请求头的所有行，用逗号连接，因此列表头中的每一跳都不会丢失。 requestHeader returns every line of a request header
+// joined with commas, so no hop of a list header sent on several lines is lost.
 func (c *Context) requestHeader(key string) string {
-	return c.Request.Header.Get(key)
+	return strings.Join(c.Request.Header.Values(key), ",")
 }
 func (c *Context) Next() {
+	c.checkReleased()
 	c.index++
 	for c.index < int8(len(c.handlers)) {
 		c.handlers[c.index](c)
@@ -67,26 +89,56 @@ func (c *Context) Next() {
 	}
 }
 
+// Param 返回URL参数的值，是 c.Params.ByName(key) 的快捷方式。 Param returns the value of the URL param, a shortcut for c.Params.ByName(key).
+//     router.GET("/user/:id<int>", func(c *gin_web.Context) {
+//         // a GET request to /user/john will not match this route
+//         id := c.Params.Int("id") // id == 42 for /user/42
+//     })
+func (c *Context) Param(key string) string {
+	c.checkReleased()
+	return c.Params.ByName(key)
+}
+
+// ClientIP 返回客户端IP。 ClientIP returns the client IP.
+// 只有当RemoteAddr是可信代理时才使用RemoteIPHeaders，它们从右到左遍历，跳过可信的跳。 RemoteIPHeaders are only used when RemoteAddr is
+// a trusted proxy, they are walked right-to-left skipping the trusted hops. See Engine.SetTrustedProxies.
+// 遍历在第一个不可信的跳处停止；如果遇到无效的跳，则返回RemoteAddr。 The walk stops at the first untrusted hop, RemoteAddr is returned
+// if an invalid hop is met before it.
 func (c *Context) ClientIP() string {
-	if c.engine.ForwardedByClientIP {
-		clientIP := c.requestHeader("X-Formwarded-For")
-		clientIP = strings.TrimSpace(strings.Split(clientIP, ",")[0])
-		if clientIP == "" {
-			clientIP = strings.TrimSpace(c.requestHeader("X-Real-Ip"))
-		}
-		if clientIP != "" {
-			return clientIP
+	c.checkReleased()
+	//平台头只包含一个IP，重复的行使其无效 // a platform header holds a single IP, repeated lines make it invalid
+	if platform := c.engine.TrustedPlatform; platform != "" {
+		if addr := c.requestHeader(platform); net.ParseIP(addr) != nil {
+			return addr
 		}
 	}
 	if c.engine.AppEngine {
-		if addr := c.requestHeader("X-Appenigne-Remote-Addr"); addr != "" {
+		if addr := c.requestHeader(PlatformGoogleAppEngine); net.ParseIP(addr) != nil {
 			return addr
 		}
 	}
-	if ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr)); err == nil {
-		return ip
+	ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		return ""
 	}
-	return ""
+	remoteIP := net.ParseIP(ip)
+	if remoteIP == nil {
+		return ""
+	}
+	if c.engine.ForwardedByClientIP && c.engine.isTrustedProxy(remoteIP) {
+		for _, name := range c.engine.RemoteIPHeaders {
+			value := c.requestHeader(name)
+			if value == "" {
+				continue
+			}
+			//第一个存在的头是最终的，无效的跳不会回退到下一个头 // the first present header is final, an invalid hop never falls through to the next header
+			if clientIP, ok := c.engine.clientIPFromHeader(name, value); ok {
+				return clientIP
+			}
+			break
+		}
+	}
+	return remoteIP.String()
 }
 
 //错误将错误附加到当前上下文。 错误被推送到错误列表。 // Error attaches an error to the current context. The error is pushed to a list of errors.
@@ -96,6 +148,7 @@ func (c *Context) ClientIP() string {
 //如果err为nil，错误将惊慌。 // Error will panic if err is nil.
 
 func (c *Context) Error(err error) *Error {
+	c.checkReleased()
 	if err == nil {
 		panic("err is nil")
 	}
@@ -112,6 +165,7 @@ func (c *Context) Error(err error) *Error {
 
 //状态设置HTTP响应代码 //Status sets the HTTP response code
 func (c *Context) Status(code int) {
+	c.checkReleased()
 	c.Writer.WriteHeader(code)
 }
 
@@ -127,6 +181,15 @@ func (c *Context) Abort() {
 //有关更多详细信息，请参见Context.Error（）。 // See Context.Error() for more details.
 func (c *Context) AbortWithStatus(code int) {
 	c.Status(code)
+	c.Writer.WriteHeaderNow()
+	c.Abort()
+}
+
+// AbortWithError内部调用`AbortWithStatus（）`和`Error（）`。 AbortWithError calls `AbortWithStatus()` and `Error()` internally.
+// 此方法停止链，写入状态代码，并将指定的错误推送到`c.Errors`。 This method stops the chain, writes the status code and pushes the specified error to `c.Errors`.
+func (c *Context) AbortWithError(code int, err error) *Error {
+	c.AbortWithStatus(code)
+	return c.Error(err)
 }
 
 func (c *Context) reset() {
@@ -141,6 +204,59 @@ func (c *Context) reset() {
 	c.Accepted = nil
 	c.queryCache = nil
 	c.formCache = nil
+	atomic.AddUint32(&c.generation, 1)
+}
+
+// Copy返回当前上下文的副本，可以在请求范围之外安全地使用它。 Copy returns a copy of the current context that can be safely used outside the request's scope.
+// 当必须将上下文传递给goroutine时，必须使用此方法。 This has to be used when the context has to be passed to a goroutine.
+// 副本是只读快照：它包含请求、参数、键、完整路径和错误，不能写入响应，也永远不会被池回收。
+// The copy is a read-only snapshot of the request, params, keys, full path and errors: it can't write the response,
+// its handler chain is aborted, and it is never recycled by the pool.
+func (c *Context) Copy() *Context {
+	c.checkReleased()
+	cp := Context{
+		writermem: c.writermem,
+		Request:   c.Request,
+		fullPath:  c.fullPath,
+		engine:    c.engine,
+		KeysMutex: &sync.RWMutex{},
+		Accepted:  append([]string(nil), c.Accepted...),
+		index:     abortIndex,
+	}
+	cp.writermem.ResponseWriter = deadWriter(errCopyWriter)
+	cp.Writer = &cp.writermem
+
+	cp.Params = make(Params, len(c.Params))
+	copy(cp.Params, c.Params)
+
+	cp.Errors = make(errorMsgs, len(c.Errors))
+	copy(cp.Errors, c.Errors)
+
+	c.KeysMutex.RLock()
+	cp.Keys = make(map[string]interface{}, len(c.Keys))
+	for k, v := range c.Keys {
+		cp.Keys[k] = v
+	}
+	c.KeysMutex.RUnlock()
+	return &cp
+}
+
+// release 在请求结束后、上下文放回池中之前被调用。 release is called once the request finished, before the context goes back to the pool.
+// 它推进generation，以便在上下文被重用之前的任何使用都会panic，而不是悄悄读取已完成请求的数据。 It advances the generation so any use
+// before the context is reused panics instead of silently reading the data of a finished request.
+// 响应写入器也会被替换，请求结束后的写入会panic，而不是写入已完成的响应。
+// The response writer is replaced too, so a write after the request finished panics instead of hitting a finished response.
+func (c *Context) release() {
+	c.writermem.ResponseWriter = deadWriter(errReleasedWriter)
+	atomic.AddUint32(&c.generation, 1)
+}
+
+// checkReleased 如果在请求结束后使用上下文，则会panic。 checkReleased panics if the context is used after the request finished.
+func (c *Context) checkReleased() {
+	if gen := atomic.LoadUint32(&c.generation); gen != 0 && gen%2 == 0 {
+		panic("gin-web: Context 在请求结束后被使用，请将 c.Copy() 传递给goroutine " +
+			"Context used after the request finished, pass c.Copy() to goroutines instead")
+	}
 }
 
 // bodyAllowedForStatus is a copy of http.bodyAllowedForStatus non-exported function
@@ -158,6 +274,7 @@ func bodyAllowedForStatus(status int) bool {
 
 // Render writes the response headers and calls render .Render to render data.
 func (c *Context) Render(code int, r render.Render) {
+	c.checkReleased()
 	c.Status(code)
 
 	if !bodyAllowedForStatus(code) {
@@ -171,6 +288,248 @@ func (c *Context) Render(code int, r render.Render) {
 	}
 }
 
+/************************************/
+/******** 输入数据 INPUT DATA ********/
+/************************************/
+
+// Query返回键控url查询值（如果存在），否则返回一个空字符串`（“”）`。 Query returns the keyed url query value if it exists,
+// otherwise it returns an empty string `("")`.
+// It is shortcut for `c.Request.URL.Query().Get(key)`
+//     GET /path?id=1234&name=Manu&value=
+// 	   c.Query("id") == "1234"
+// 	   c.Query("name") == "Manu"
+// 	   c.Query("value") == ""
+// 	   c.Query("wtf") == ""
+func (c *Context) Query(key string) string {
+	value, _ := c.GetQuery(key)
+	return value
+}
+
+// DefaultQuery返回键控的url查询值（如果存在），否则，返回指定的defaultValue字符串。 DefaultQuery returns the keyed url query value if it exists,
+// otherwise it returns the specified defaultValue string.
+// See: Query() and GetQuery() for further information.
+//     GET /?name=Manu&lastname=
+//     c.DefaultQuery("name", "unknown") == "Manu"
+//     c.DefaultQuery("id", "none") == "none"
+//     c.DefaultQuery("lastname", "none") == ""
+func (c *Context) DefaultQuery(key, defaultValue string) string {
+	if value, ok := c.GetQuery(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// GetQuery类似于Query（），如果存在`（value，true）`，它将返回键控的url查询值 GetQuery is like Query(), it returns the keyed url query value
+// if it exists `(value, true)` (even when the value is an empty string),
+// otherwise it returns `("", false)`.
+func (c *Context) GetQuery(key string) (string, bool) {
+	c.initQueryCache()
+	if values, ok := c.queryCache[key]; ok && len(values) > 0 {
+		return values[0], true
+	}
+	return "", false
+}
+
+func (c *Context) initQueryCache() {
+	if c.queryCache == nil {
+		if c.Request != nil {
+			c.queryCache = c.Request.URL.Query()
+		} else {
+			c.queryCache = url.Values{}
+		}
+	}
+}
+
+/************************************/
+/************ 绑定 BINDING **********/
+/************************************/
+
+// Bind根据方法和Content-Type检查要自动选择绑定引擎， Bind checks the Content-Type to select a binding engine automatically,
+// 根据“ Content-Type”标头，使用不同的绑定： Depending the "Content-Type" header different bindings are used:
+//     "application/json" --> JSON binding
+//     "application/xml"  --> XML binding
+// 否则->返回错误。 otherwise --> returns an error.
+// 如果Content-Type ==“ application / json”，则使用JSON或XML作为JSON输入来解析请求的正文。 It parses the request's body as JSON if Content-Type == "application/json" using JSON or XML as a JSON input.
+// 它将json有效负载解码为指定为指针的结构。 It decodes the json payload into the struct specified as a pointer.
+// 如果输入无效，它将写入400错误并在响应中设置Content-Type标头“ text / plain”。 It writes a 400 error and sets Content-Type header "text/plain" in the response if input is not valid.
+func (c *Context) Bind(obj interface{}) error {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	return c.MustBindWith(obj, b)
+}
+
+// BindJSON是c.MustBindWith（obj，binding.JSON）的快捷方式。 BindJSON is a shortcut for c.MustBindWith(obj, binding.JSON).
+func (c *Context) BindJSON(obj interface{}) error {
+	return c.MustBindWith(obj, binding.JSON)
+}
+
+// BindXML是c.MustBindWith（obj，binding.BindXML）的快捷方式。 BindXML is a shortcut for c.MustBindWith(obj, binding.BindXML).
+func (c *Context) BindXML(obj interface{}) error {
+	return c.MustBindWith(obj, binding.XML)
+}
+
+// BindQuery是c.MustBindWith（obj，binding.Query）的快捷方式。 BindQuery is a shortcut for c.MustBindWith(obj, binding.Query).
+func (c *Context) BindQuery(obj interface{}) error {
+	return c.MustBindWith(obj, binding.Query)
+}
+
+// BindHeader是c.MustBindWith（obj，binding.Header）的快捷方式。 BindHeader is a shortcut for c.MustBindWith(obj, binding.Header).
+func (c *Context) BindHeader(obj interface{}) error {
+	return c.MustBindWith(obj, binding.Header)
+}
+
+// BindUri使用binding.Uri绑定传递的结构指针。 BindUri binds the passed struct pointer using binding.Uri.
+// 如果发生错误，它将以HTTP 400中止请求。 It will abort the request with HTTP 400 if any error occurs.
+func (c *Context) BindUri(obj interface{}) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind) // nolint: errcheck
+		return err
+	}
+	return nil
+}
+
+// MustBindWith使用指定的绑定引擎绑定传递的结构指针。 MustBindWith binds the passed struct pointer using the specified binding engine.
+// 如果发生错误，它将以HTTP 400中止请求，并将错误以ErrorTypeBind记录到c.Errors中。 It will abort the request with HTTP 400 and record the error in c.Errors as ErrorTypeBind if any error occurs.
+// 请参阅绑定包。 See the binding package.
+func (c *Context) MustBindWith(obj interface{}, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind) // nolint: errcheck
+		return err
+	}
+	return nil
+}
+
+// ShouldBind检查Content-Type以自动选择绑定引擎， ShouldBind checks the Content-Type to select a binding engine automatically,
+// 根据“ Content-Type”标头，使用不同的绑定： Depending the "Content-Type" header different bindings are used:
+//     "application/json" --> JSON binding
+//     "application/xml"  --> XML binding
+// 与c.Bind（）类似，但是此方法不会将响应状态代码设置为400，也不会中止或记录错误。 Like c.Bind() but this method does not set the response status code to 400, abort or record the error.
+func (c *Context) ShouldBind(obj interface{}) error {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	return c.ShouldBindWith(obj, b)
+}
+
+// ShouldBindJSON是c.ShouldBindWith（obj，binding.JSON）的快捷方式。 ShouldBindJSON is a shortcut for c.ShouldBindWith(obj, binding.JSON).
+func (c *Context) ShouldBindJSON(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.JSON)
+}
+
+// ShouldBindXML是c.ShouldBindWith（obj，binding.XML）的快捷方式。 ShouldBindXML is a shortcut for c.ShouldBindWith(obj, binding.XML).
+func (c *Context) ShouldBindXML(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.XML)
+}
+
+// ShouldBindQuery是c.ShouldBindWith（obj，binding.Query）的快捷方式。 ShouldBindQuery is a shortcut for c.ShouldBindWith(obj, binding.Query).
+func (c *Context) ShouldBindQuery(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.Query)
+}
+
+// ShouldBindHeader是c.ShouldBindWith（obj，binding.Header）的快捷方式。 ShouldBindHeader is a shortcut for c.ShouldBindWith(obj, binding.Header).
+func (c *Context) ShouldBindHeader(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.Header)
+}
+
+// ShouldBindUri使用指定的绑定引擎从c.Params绑定传递的结构指针。 ShouldBindUri binds the passed struct pointer from c.Params using the specified binding engine.
+func (c *Context) ShouldBindUri(obj interface{}) error {
+	m := make(map[string][]string)
+	for _, v := range c.Params {
+		m[v.Key] = []string{v.Value}
+	}
+	return binding.Uri.BindUri(m, obj)
+}
+
+// ShouldBindWith使用指定的绑定引擎绑定传递的结构指针。 ShouldBindWith binds the passed struct pointer using the specified binding engine.
+// 请参阅绑定包。 See the binding package.
+func (c *Context) ShouldBindWith(obj interface{}, b binding.Binding) error {
+	return b.Bind(c.Request, obj)
+}
+
+// ContentType返回请求的Content-Type标头，不带参数。 ContentType returns the Content-Type header of the request, without parameters.
+func (c *Context) ContentType() string {
+	return filterFlags(c.requestHeader("Content-Type"))
+}
+
+// filterFlags 去掉诸如 "; charset=utf-8" 的参数 filterFlags strips parameters such as "; charset=utf-8"
+func filterFlags(content string) string {
+	for i, char := range content {
+		if char == ' ' || char == ';' {
+			return content[:i]
+		}
+	}
+	return content
+}
+
+// File以一种有效的方式将指定的文件写入主体流。 File writes the specified file into the body stream in an efficient way.
+func (c *Context) File(filepath string) {
+	http.ServeFile(c.Writer, c.Request, filepath)
+}
+
+// IndentedJSON将给定的结构序列化为漂亮的JSON（缩进+结束行）到响应主体中。 IndentedJSON serializes the given struct as pretty JSON (indented + endlines) into the response body.
+// 它还将Content-Type设置为“ application / json”。 It also sets the Content-Type as "application/json".
+// 警告：我们建议仅将其用于开发目的，因为打印漂亮的JSON会占用更多CPU和带宽。 WARNING: we recommend to use this only for development purposes since printing pretty JSON is
+// 请改用Context.JSON（）。 more CPU and bandwidth consuming. Use Context.JSON() instead.
+func (c *Context) IndentedJSON(code int, obj interface{}) {
+	c.Render(code, render.IndentedJSON{Data: obj})
+}
+
+// SecureJSON将给定的结构序列化为安全JSON到响应主体中。 SecureJSON serializes the given struct as Secure JSON into the response body.
+// 如果给定的结构是数组值，则默认在响应主体前添加“ while（1）”。 Default prepends "while(1)," to response body if the given struct is array values.
+// 它还将Content-Type设置为“ application / json”。 It also sets the Content-Type as "application/json".
+// 前缀可以用 Engine.SecureJsonPrefix 修改。 The prefix can be changed with Engine.SecureJsonPrefix.
+func (c *Context) SecureJSON(code int, obj interface{}) {
+	c.Render(code, render.SecureJSON{Prefix: c.engine.secureJsonPrefix, Data: obj})
+}
+
+// JSONP将给定的结构作为JSON序列化到响应主体中。 JSONP serializes the given struct as JSON into the response body.
+// 它将填充添加到响应主体，以从位于与客户端不同的域中的服务器请求数据。 It adds padding to response body to request data from a server residing in a different domain than the client.
+// 它还将Content-Type设置为“ application / javascript”。 It also sets the Content-Type as "application/javascript".
+// 无效的callback查询参数将以400中止请求。 An invalid callback query param aborts the request with 400.
+func (c *Context) JSONP(code int, obj interface{}) {
+	callback := c.DefaultQuery("callback", "")
+	if callback == "" {
+		c.Render(code, render.JSON{Data: obj})
+		return
+	}
+	if !render.ValidJSONPCallback(callback) {
+		c.AbortWithError(http.StatusBadRequest, render.ErrInvalidCallback).SetType(ErrorTypeRender) // nolint: errcheck
+		return
+	}
+	c.Render(code, render.JsonpJSON{Callback: callback, Data: obj})
+}
+
+// JSON将给定的结构作为JSON序列化到响应主体中。 JSON serializes the given struct as JSON into the response body.
+// 它还将Content-Type设置为“ application / json”。 It also sets the Content-Type as "application/json".
+func (c *Context) JSON(code int, obj interface{}) {
+	c.Render(code, render.JSON{Data: obj})
+}
+
+// AsciiJSON使用Unicode到ASCII字符串将给定的结构作为JSON序列化到响应主体中。 AsciiJSON serializes the given struct as JSON into the response body with unicode to ASCII string.
+// 它还将Content-Type设置为“ application / json”。 It also sets the Content-Type as "application/json".
+func (c *Context) AsciiJSON(code int, obj interface{}) {
+	c.Render(code, render.AsciiJSON{Data: obj})
+}
+
+// PureJSON将给定的结构作为JSON序列化到响应主体中。 PureJSON serializes the given struct as JSON into the response body.
+// 与JSON不同，PureJSON不会用其unicode实体替换特殊的html字符。 PureJSON, unlike JSON, does not replace special html characters with their unicode entities.
+func (c *Context) PureJSON(code int, obj interface{}) {
+	c.Render(code, render.PureJSON{Data: obj})
+}
+
+// XML将给定的结构作为XML序列化到响应主体中。 XML serializes the given struct as XML into the response body.
+// 它还将Content-Type设置为“ application / xml”。 It also sets the Content-Type as "application/xml".
+func (c *Context) XML(code int, obj interface{}) {
+	c.Render(code, render.XML{Data: obj})
+}
+
+// YAML将给定的结构作为YAML序列化到响应主体中。 YAML serializes the given struct as YAML into the response body.
+func (c *Context) YAML(code int, obj interface{}) {
+	c.Render(code, render.YAML{Data: obj})
+}
+
+// String将给定的字符串写入响应主体。 String writes the given string into the response body.
+func (c *Context) String(code int, format string, values ...interface{}) {
+	c.Render(code, render.String{Format: format, Data: values})
+}
+
 // HTML呈现由其文件名指定的HTTP模板。 // HTML renders the HTTP template specified by its file name.
 //它还会更新HTTP代码，并将Content-Type设置为“ text / html // It also updates the HTTP code and sets the Content-Type as "text/html".
 // See http://golang.org/doc/articles/wiki/
@@ -178,3 +537,236 @@ func (c *Context) HTML(code int, name string, obj interface{}) {
 	instance := c.engine.HTMLRender.Instance(name, obj)
 	c.Render(code, instance)
 }
+
+/************************************/
+/***** 元数据管理 METADATA MANAGEMENT *****/
+/************************************/
+
+// Set用于专门为此上下文存储新的键/值对。 Set is used to store a new key/value pair exclusively for this context.
+// 如果以前没有使用过c.Keys，它也会延迟初始化。 It also lazy initializes  c.Keys if it was not used previously.
+func (c *Context) Set(key string, value interface{}) {
+	c.checkReleased()
+	c.KeysMutex.Lock()
+	if c.Keys == nil {
+		c.Keys = make(map[string]interface{})
+	}
+
+	c.Keys[key] = value
+	c.KeysMutex.Unlock()
+}
+
+// Get返回给定键的值，即：（value，true）。 Get returns the value for the given key, ie: (value, true).
+// 如果该值不存在，则返回（nil，false） If the value does not exists it returns (nil, false)
+func (c *Context) Get(key string) (value interface{}, exists bool) {
+	c.checkReleased()
+	c.KeysMutex.RLock()
+	value, exists = c.Keys[key]
+	c.KeysMutex.RUnlock()
+	return
+}
+
+/************************************/
+/***** GOLANG.ORG/X/NET/CONTEXT *****/
+/************************************/
+
+// Deadline 返回 c.Request.Context() 的截止时间。 Deadline returns the deadline of c.Request.Context().
+// 当请求没有截止时间时，ok为false。 ok is false when the request has no deadline set.
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	if c.Request == nil {
+		return
+	}
+	return c.Request.Context().Deadline()
+}
+
+// Done 返回一个通道，当客户端断开连接或请求被取消时关闭。 Done returns a channel that's closed when the client disconnects or the request is canceled,
+// 因此将c传递给数据库驱动或gRPC客户端时，取消会传播到这些调用中。 so cancellations propagate into database drivers or gRPC clients c is passed to.
+// 如果没有请求，Done返回nil，表示永远不会被取消。 Done returns nil, which is never closed, if there is no request.
+func (c *Context) Done() <-chan struct{} {
+	if c.Request == nil {
+		return nil
+	}
+	return c.Request.Context().Done()
+}
+
+// Err 在Done关闭后返回 c.Request.Context() 的错误，否则返回nil。 Err returns the error of c.Request.Context() after Done is closed, nil otherwise.
+func (c *Context) Err() error {
+	if c.Request == nil {
+		return nil
+	}
+	return c.Request.Context().Err()
+}
+
+// Value 返回与此上下文关联的键的值，如果没有则返回nil。 Value returns the value associated with this context for key, or nil if none.
+// 字符串键首先在c.Keys中查找，其余的委托给 c.Request.Context()。 String keys are looked up in c.Keys first, everything else is delegated to c.Request.Context().
+func (c *Context) Value(key interface{}) interface{} {
+	if keyAsString, ok := key.(string); ok {
+		if val, exists := c.Get(keyAsString); exists {
+			return val
+		}
+	}
+	if c.Request == nil {
+		return nil
+	}
+	return c.Request.Context().Value(key)
+}
+
+/************************************/
+/****** 内容协商 CONTENT NEGOTIATION ******/
+/************************************/
+
+// Negotiate 包含所有协商数据。 Negotiate contains all negotiations data.
+// 每种格式的数据为nil时使用Data。 Data is used when the data of a format is nil.
+type Negotiate struct {
+	Offered  []string
+	HTMLName string
+	HTMLData interface{}
+	JSONData interface{}
+	XMLData  interface{}
+	YAMLData interface{}
+	TextData interface{}
+	Data     interface{}
+}
+
+// Negotiate 根据可接受的Accept格式调用不同的Render。 Negotiate calls different Render according acceptable Accept format.
+// 如果没有提供的格式可以接受，则以406中止请求。 The request is aborted with 406 if none of the offered formats is acceptable.
+func (c *Context) Negotiate(code int, config Negotiate) {
+	switch c.NegotiateFormat(config.Offered...) {
+	case binding.MIMEJSON:
+		data := chooseData(config.JSONData, config.Data)
+		c.JSON(code, data)
+
+	case binding.MIMEHTML:
+		data := chooseData(config.HTMLData, config.Data)
+		c.HTML(code, config.HTMLName, data)
+
+	case binding.MIMEXML:
+		data := chooseData(config.XMLData, config.Data)
+		c.XML(code, data)
+
+	case binding.MIMEYAML:
+		data := chooseData(config.YAMLData, config.Data)
+		c.YAML(code, data)
+
+	case binding.MIMEPlain:
+		data := chooseData(config.TextData, config.Data)
+		c.String(code, "%v", data)
+
+	default:
+		c.AbortWithError(http.StatusNotAcceptable, errors.New("服务器未提供可接受的格式 the accepted formats are not offered by the server")) // nolint: errcheck
+	}
+}
+
+// NegotiateFormat 返回可接受的Accept格式。 NegotiateFormat returns an acceptable Accept format.
+// 如果设置了c.Accepted，则按其顺序选择第一个提供的格式； If c.Accepted is set, the first offered format in its order is chosen;
+// 否则解析Accept标头，选择q值最高的提供格式，q值相同时按offered的顺序。 otherwise the Accept header is parsed and the offered format
+// with the highest q-value wins, ties are broken by the order of offered. Wildcards such as text/* and */* are honored
+// and q=0 excludes a format. Without an Accept header the first offered format is returned,
+// if nothing is acceptable an empty string is returned.
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		panic("你必须至少提供一种格式 you must provide at least one offer")
+	}
+
+	if len(c.Accepted) > 0 {
+		for _, accepted := range c.Accepted {
+			for _, offer := range offered {
+				if mimeMatches(accepted, offer) {
+					return offer
+				}
+			}
+		}
+		return ""
+	}
+
+	ranges := parseAccept(c.requestHeader("Accept"))
+	if len(ranges) == 0 {
+		return offered[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offered {
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// SetAccepted 设置Accept标头数据。 SetAccepted sets Accept header data.
+func (c *Context) SetAccepted(formats ...string) {
+	c.Accepted = formats
+}
+
+func chooseData(custom, wildcard interface{}) interface{} {
+	if custom != nil {
+		return custom
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	panic("协商配置无效 negotiation config is invalid")
+}
+
+// acceptRange 是Accept标头中的单个媒体范围及其q值 acceptRange is a single media range of the Accept header and its q-value.
+type acceptRange struct {
+	mime string
+	q    float64
+}
+
+// parseAccept 解析Accept标头，按q值降序排列。 parseAccept parses the Accept header, ordered by descending q-value.
+func parseAccept(acceptHeader string) []acceptRange {
+	parts := strings.Split(acceptHeader, ",")
+	out := make([]acceptRange, 0, len(parts))
+	for _, part := range parts {
+		params := strings.Split(part, ";")
+		mime := strings.ToLower(strings.TrimSpace(params[0]))
+		if mime == "" {
+			continue
+		}
+		r := acceptRange{mime: mime, q: 1}
+		for _, param := range params[1:] {
+			kv := strings.SplitN(param, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil && q >= 0 && q <= 1 {
+				r.q = q
+			}
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].q > out[j].q })
+	return out
+}
+
+// acceptQuality 返回最具体的匹配范围对offer给出的q值，没有匹配时为0。 acceptQuality returns the q-value the most specific matching range gives offer, 0 if none matches.
+func acceptQuality(ranges []acceptRange, offer string) float64 {
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		if !mimeMatches(r.mime, offer) {
+			continue
+		}
+		s := 2
+		if r.mime == "*/*" {
+			s = 0
+		} else if strings.HasSuffix(r.mime, "/*") {
+			s = 1
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// mimeMatches 报告媒体范围（可能带有通配符）是否匹配offer。 mimeMatches reports whether a media range, possibly with wildcards, matches offer.
+func mimeMatches(accepted, offer string) bool {
+	accepted, offer = strings.ToLower(accepted), strings.ToLower(offer)
+	if accepted == "*/*" || accepted == "*" || accepted == offer {
+		return true
+	}
+	if strings.HasSuffix(accepted, "/*") {
+		return strings.HasPrefix(offer, accepted[:len(accepted)-1])
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package gin_web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextCopyWriterPanics(t *testing.T) {
+	router := New()
+	var cp *Context
+	router.GET("/users/:id", func(c *Context) {
+		c.Set("user", "gin")
+		cp = c.Copy()
+		c.String(http.StatusOK, "ok")
+	})
+	if w := performRequest(router, "GET", "/users/1"); w.Code != http.StatusOK {
+		t.Fatalf("GET /users/1 = %d", w.Code)
+	}
+
+	if user, _ := cp.Get("user"); cp.Param("id") != "1" || cp.fullPath != "/users/:id" || user != "gin" {
+		t.Errorf("copy lost request data: id=%q path=%q user=%v", cp.Param("id"), cp.fullPath, user)
+	}
+
+	tests := []struct {
+		name string
+		use  func()
+	}{
+		{"String", func() { cp.String(http.StatusOK, "late") }},
+		{"Header", func() { cp.Writer.Header().Set("X-Late", "1") }},
+		{"Writer.Write", func() { cp.Writer.Write([]byte("late")) }},
+		{"Writer.Hijack", func() { cp.Writer.Hijack() }},
+	}
+	for _, tt := range tests {
+		recv := catchPanic(tt.use)
+		if msg, _ := recv.(string); !strings.Contains(msg, "response writer used on a Context copy") {
+			t.Errorf("%s on a copy: got panic %v", tt.name, recv)
+		}
+	}
+}
+
+func TestContextWriterAfterRelease(t *testing.T) {
+	for _, mode := range []string{DebugMode, ReleaseMode} {
+		t.Run(mode, func(t *testing.T) {
+			previous := Mode()
+			t.Cleanup(func() { SetMode(previous) })
+			SetMode(mode)
+
+			router := New()
+			var leaked *Context
+			router.GET("/", func(c *Context) { leaked = c })
+			performRequest(router, "GET", "/")
+
+			recv := catchPanic(func() { leaked.Writer.WriteString("late") })
+			if msg, _ := recv.(string); !strings.Contains(msg, "response writer used after the request finished") {
+				t.Errorf("write after release: got panic %v", recv)
+			}
+			recv = catchPanic(func() { leaked.Set("late", true) })
+			if msg, _ := recv.(string); !strings.Contains(msg, "Context used after the request finished") {
+				t.Errorf("Set after release: got panic %v", recv)
+			}
+
+			//池中的上下文在下一个请求中再次可用 // a pooled context is usable again for the next request
+			router.GET("/ok", func(c *Context) { c.Set("k", 1); c.String(http.StatusOK, "ok") })
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+			if w.Body.String() != "ok" {
+				t.Errorf("GET /ok = %q after release", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetMode(t *testing.T) {
+	previous := Mode()
+	t.Cleanup(func() { SetMode(previous) })
+
+	for _, tt := range []struct {
+		in, want string
+		debug    bool
+	}{{"", DebugMode, true}, {ReleaseMode, ReleaseMode, false}, {TestMode, TestMode, false}, {DebugMode, DebugMode, true}} {
+		SetMode(tt.in)
+		if Mode() != tt.want || IsDebugging() != tt.debug {
+			t.Errorf("SetMode(%q): Mode() = %q, IsDebugging() = %v", tt.in, Mode(), IsDebugging())
+		}
+	}
+	if recv := catchPanic(func() { SetMode("unknown") }); recv == nil {
+		t.Error("SetMode accepted an unknown mode")
+	}
+}
+
+type contextTestKey struct{}
+
+func TestContextRequestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextTestKey{}, "from request"))
+	defer cancel()
+	router := New()
+	router.GET("/", func(c *Context) {
+		c.Set("user", "from keys")
+		if c.Value(contextTestKey{}) != "from request" {
+			t.Errorf("Value(contextTestKey{}) = %v, want the request context value", c.Value(contextTestKey{}))
+		}
+		if c.Value("user") != "from keys" || c.Value("missing") != nil {
+			t.Errorf("Value(user) = %v, Value(missing) = %v", c.Value("user"), c.Value("missing"))
+		}
+		select {
+		case <-c.Done():
+			t.Error("Done closed before the request was canceled")
+		default:
+		}
+		if c.Err() != nil {
+			t.Errorf("Err() = %v before cancel", c.Err())
+		}
+
+		//派生的上下文随请求一起被取消 // a derived context is canceled together with the request
+		child, stop := context.WithTimeout(c, time.Hour)
+		defer stop()
+		cancel()
+		select {
+		case <-c.Done():
+		case <-time.After(time.Second):
+			t.Fatal("Done not closed after the request was canceled")
+		}
+		if c.Err() != context.Canceled {
+			t.Errorf("Err() = %v, want context.Canceled", c.Err())
+		}
+		select {
+		case <-child.Done():
+		case <-time.After(time.Second):
+			t.Fatal("derived context not canceled")
+		}
+		if child.Value(contextTestKey{}) != "from request" {
+			t.Errorf("derived Value = %v", child.Value(contextTestKey{}))
+		}
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+	empty := &Context{}
+	if empty.Done() != nil || empty.Err() != nil || empty.Value(contextTestKey{}) != nil {
+		t.Error("a Context without a request should never be done and carry no values")
+	}
+	if _, ok := empty.Deadline(); ok {
+		t.Error("a Context without a request should have no deadline")
+	}
+}
+
+func TestContextBind(t *testing.T) {
+	type target struct {
+		Name string `json:"name" form:"name" uri:"name"`
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		bind        func(c *Context, obj interface{}) error
+		want        string
+		code        int
+	}{
+		{"Bind json", "application/json", `{"name":"gin"}`, (*Context).Bind, "gin", http.StatusOK},
+		{"Bind form", "application/x-www-form-urlencoded", "name=gin", (*Context).Bind, "gin", http.StatusOK},
+		{"Bind invalid json", "application/json", `{"name":`, (*Context).Bind, "", http.StatusBadRequest},
+		{"BindJSON", "", `{"name":"gin"}`, (*Context).BindJSON, "gin", http.StatusOK},
+		{"BindJSON invalid", "", `[1]`, (*Context).BindJSON, "", http.StatusBadRequest},
+		{"BindUri", "", "", (*Context).BindUri, "users", http.StatusOK},
+		{"ShouldBind invalid", "application/json", `{"name":`, (*Context).ShouldBind, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		router := New()
+		var errs errorMsgs
+		router.POST("/:name", func(c *Context) {
+			var obj target
+			if err := tt.bind(c, &obj); err == nil && obj.Name != tt.want {
+				t.Errorf("%s: bound %q, want %q", tt.name, obj.Name, tt.want)
+			}
+			errs = c.Errors
+		})
+		req := httptest.NewRequest("POST", "/users", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.code)
+		}
+		if tt.code == http.StatusBadRequest {
+			if len(errs) != 1 || errs[0].Type != ErrorTypeBind {
+				t.Errorf("%s: c.Errors = %v, want one ErrorTypeBind error", tt.name, errs)
+			}
+		} else if len(errs) != 0 {
+			t.Errorf("%s: unexpected c.Errors %v", tt.name, errs)
+		}
+	}
+}
+
+func TestContextJSONHelpers(t *testing.T) {
+	router := New()
+	router.GET("/secure", func(c *Context) { c.SecureJSON(http.StatusOK, []string{"a"}) })
+	router.GET("/secure-object", func(c *Context) { c.SecureJSON(http.StatusOK, map[string]int{"a": 1}) })
+	router.GET("/jsonp", func(c *Context) { c.JSONP(http.StatusOK, map[string]int{"a": 1}) })
+	router.GET("/ascii", func(c *Context) { c.AsciiJSON(http.StatusOK, "语") })
+	router.GET("/pure", func(c *Context) { c.PureJSON(http.StatusOK, "<b>") })
+	router.GET("/json", func(c *Context) { c.JSON(http.StatusCreated, "<b>") })
+
+	tests := []struct {
+		path        string
+		code        int
+		body        string
+		contentType string
+	}{
+		{"/secure", http.StatusOK, `while(1)["a"]`, "application/json; charset=utf-8"},
+		{"/secure-object", http.StatusOK, `{"a":1}`, "application/json; charset=utf-8"},
+		{"/jsonp?callback=cb", http.StatusOK, `/**/ typeof cb === 'function' && cb({"a":1});`, "application/javascript; charset=utf-8"},
+		{"/jsonp", http.StatusOK, `{"a":1}`, "application/json; charset=utf-8"},
+		{"/jsonp?callback=alert(1)", http.StatusBadRequest, "", ""},
+		{"/ascii", http.StatusOK, `"\u8bed"`, "application/json"},
+		{"/pure", http.StatusOK, "\"<b>\"\n", "application/json; charset=utf-8"},
+		{"/json", http.StatusCreated, `"\u003cb\u003e"`, "application/json; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		w := performRequest(router, "GET", tt.path)
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("GET %s: Content-Type = %q, want %q", tt.path, ct, tt.contentType)
+		}
+	}
+
+	router.SecureJsonPrefix(")]}',\n")
+	if w := performRequest(router, "GET", "/secure"); w.Body.String() != ")]}',\n[\"a\"]" {
+		t.Errorf("custom prefix: got %q", w.Body.String())
+	}
+
+	var errs errorMsgs
+	router.GET("/jsonp-errors", func(c *Context) {
+		c.JSONP(http.StatusOK, 1)
+		errs = c.Errors
+	})
+	performRequest(router, "GET", "/jsonp-errors?callback=1x")
+	if len(errs) != 1 || errs[0].Type != ErrorTypeRender {
+		t.Errorf("invalid callback: c.Errors = %v, want one ErrorTypeRender error", errs)
+	}
+}
+
+func TestContextNegotiateFormat(t *testing.T) {
+	const (
+		json = "application/json"
+		xml  = "application/xml"
+		html = "text/html"
+		text = "text/plain"
+	)
+	tests := []struct {
+		name     string
+		accept   string
+		accepted []string
+		offered  []string
+		want     string
+	}{
+		{"no header", "", nil, []string{json, xml}, json},
+		{"exact", "application/xml", nil, []string{json, xml}, xml},
+		{"q ordering", "application/json;q=0.5, application/xml;q=0.9", nil, []string{json, xml}, xml},
+		{"tie keeps offered order", "application/xml, application/json", nil, []string{json, xml}, json},
+		{"type wildcard", "text/*", nil, []string{json, html}, html},
+		{"any wildcard", "*/*", nil, []string{xml, json}, xml},
+		{"specific beats wildcard", "*/*;q=0.9, text/plain;q=0.1", nil, []string{text, json}, json},
+		{"q=0 excludes", "application/json;q=0, */*", nil, []string{json, xml}, xml},
+		{"nothing acceptable", "image/png", nil, []string{json, xml}, ""},
+		{"case insensitive", "Application/XML", nil, []string{json, xml}, xml},
+		{"invalid q ignored", "application/xml;q=2, application/json;q=0.5", nil, []string{json, xml}, xml},
+		{"accepted overrides header", "application/json", []string{xml}, []string{json, xml}, xml},
+		{"accepted wildcard", "", []string{"text/*"}, []string{json, text}, text},
+		{"accepted order wins", "", []string{json, xml}, []string{xml, json}, json},
+		{"accepted nothing offered", "application/json", []string{html}, []string{json}, ""},
+	}
+	for _, tt := range tests {
+		router := New()
+		var got string
+		router.GET("/", func(c *Context) {
+			if tt.accepted != nil {
+				c.SetAccepted(tt.accepted...)
+			}
+			got = c.NegotiateFormat(tt.offered...)
+		})
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", tt.accept)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		if got != tt.want {
+			t.Errorf("%s: NegotiateFormat() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if recv := catchPanic(func() { (&Context{}).NegotiateFormat() }); recv == nil {
+		t.Error("NegotiateFormat without offers should panic")
+	}
+}
+
+func TestContextNegotiate(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) {
+		c.Negotiate(http.StatusOK, Negotiate{
+			Offered:  []string{"application/json", "application/xml", "text/plain"},
+			Data:     map[string]string{"a": "b"},
+			XMLData:  "xml",
+			TextData: "text",
+		})
+	})
+
+	tests := []struct {
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"application/json", http.StatusOK, "application/json; charset=utf-8", `{"a":"b"}`},
+		{"text/*", http.StatusOK, "text/plain; charset=utf-8", "text"},
+		{"application/xml;q=0.2, text/plain;q=0.1", http.StatusOK, "application/xml; charset=utf-8", "<string>xml</string>"},
+		{"image/png", http.StatusNotAcceptable, "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code || w.Header().Get("Content-Type") != tt.contentType || tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("Accept %q: got %d %q %q, want %d %q %q", tt.accept, w.Code, w.Header().Get("Content-Type"), w.Body.String(), tt.code, tt.contentType, tt.body)
+		}
+	}
+}
@@ -5,22 +5,31 @@ import (
 	"github.com/sourcecmdb/gin-web/utils"
 	"html/template"
 	"io"
+	"log/slog"
 	"os"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
 const gin_webSupporMinGoVer = 0
 
-//DefaultErrorWriter是GIn-Web用于调试错误的默认io.Writer  DefaultErrorWriter is the default io.Writer used by GIn-web to debug errors
+// DefaultErrorWriter是GIn-Web用于调试错误的默认io.Writer  DefaultErrorWriter is the default io.Writer used by GIn-web to debug errors
 var DefaultErrorWriter io.Writer = os.Stderr
 
 func IsDebugging() bool {
-	return ginMode == debugCode
+	return atomic.LoadInt32(&ginMode) == debugCode
 }
+
+// DebugPrint 在调试模式下打印调试消息，如果调用了SetDebugLogger，则以Debug级别写入slog。 DebugPrint prints a debug message in debug mode,
+// to slog at the Debug level if SetDebugLogger was called.
 func DebugPrint(format string, values ...interface{}) {
 	if IsDebugging() {
+		if logger := loadDebugLogger(); logger != nil {
+			slogDebug(logger, format, values)
+			return
+		}
 		if !strings.HasSuffix(format, "\n") {
 			format += "\n"
 		}
@@ -75,14 +84,25 @@ router.SetHTMLTemplate（template）// <<好地方
 `)
 }
 
-//DebugPrintRouteFunc指示调试日志输出格式。  //DebugPrintRouteFunc indicates debug log output format.
+// DebugPrintRouteFunc指示调试日志输出格式。  //DebugPrintRouteFunc indicates debug log output format.
 var DebugPrintRouteFunc func(httpMethod, absolutePath, handlerName string, nuHandlers int)
 
-func debugPrintRoute(httpMethod, absolutePath string, handlers HandlersChain) {
+// debugPrintRoute 打印注册的路由，写入引擎的日志记录器，没有时写入SetDebugLogger设置的日志记录器。 debugPrintRoute prints a registered route
+// to the logger of the engine, or to the one set by SetDebugLogger if the engine has none.
+func debugPrintRoute(logger *slog.Logger, httpMethod, absolutePath string, handlers HandlersChain) {
 	if IsDebugging() {
 		nuHandlers := len(handlers)
 		handlerName := utils.NameOfFunction(handlers.Last())
-		if DebugPrintRouteFunc == nil {
+		if logger == nil {
+			logger = loadDebugLogger()
+		}
+		if logger != nil && DebugPrintRouteFunc == nil {
+			slogDebug(logger, "route registered", nil,
+				slog.String("method", httpMethod),
+				slog.String("path", absolutePath),
+				slog.String("handler", handlerName),
+				slog.Int("handlers", nuHandlers))
+		} else if DebugPrintRouteFunc == nil {
 			DebugPrint("%-6s %-25s --> %s (%d hanlders)\n", httpMethod, absolutePath, handlerName, nuHandlers)
 		} else {
 			DebugPrintRouteFunc(httpMethod, absolutePath, handlerName, nuHandlers)
@@ -93,6 +113,10 @@ func debugPrintRoute(httpMethod, absolutePath string, handlers HandlersChain) {
 func debugPrintError(err error) {
 	if err != nil {
 		if IsDebugging() {
+			if logger := loadDebugLogger(); logger != nil {
+				logger.Error(err.Error())
+				return
+			}
 			fmt.Fprintf(DefaultErrorWriter, "[GIN_WEB-debug][ERROR] %v\n", err)
 		}
 	}
@@ -1,7 +1,9 @@
 package gin_web
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/sourcecmdb/gin-web/binding"
 	"strings"
 )
 
@@ -30,6 +32,41 @@ type Error struct {
 	Meta interface{}
 }
 
+// SetType设置错误的类型。 SetType sets the error's type.
+func (msg *Error) SetType(flags ErrorType) *Error {
+	msg.Type = flags
+	return msg
+}
+
+// SetMeta设置错误的元数据。 SetMeta sets the error's meta data.
+func (msg *Error) SetMeta(data interface{}) *Error {
+	msg.Meta = data
+	return msg
+}
+
+// Error实现错误接口。 Error implements the error interface.
+func (msg *Error) Error() string {
+	return msg.Err.Error()
+}
+
+// JSON创建格式正确的JSON。 JSON creates a properly formatted JSON.
+// 绑定验证错误以 binding.ValidationErrors 的形式放在"fields"中。 Binding validation failures are put under "fields" as binding.ValidationErrors.
+func (msg *Error) JSON() interface{} {
+	jsonData := map[string]interface{}{"error": msg.Error()}
+	if fields, ok := msg.Err.(binding.ValidationErrors); ok {
+		jsonData["fields"] = fields
+	}
+	if msg.Meta != nil {
+		jsonData["meta"] = msg.Meta
+	}
+	return jsonData
+}
+
+// MarshalJSON实现json.Marshaller接口。 MarshalJSON implements the json.Marshaller interface.
+func (msg *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(msg.JSON())
+}
+
 // IsType判断一个错误 IsType judges one error
 func (msg *Error) IsType(flags ErrorType) bool {
 	return (msg.Type & flags) > 0
@@ -56,6 +93,36 @@ func (a errorMsgs) ByType(typ ErrorType) errorMsgs {
 	return result
 }
 
+// JSON创建包含所有错误的JSON数组，只有一个错误时为该错误本身。 JSON creates a JSON array with every error, or the error itself if there is only one.
+func (a errorMsgs) JSON() interface{} {
+	switch length := len(a); length {
+	case 0:
+		return nil
+	case 1:
+		return a.Last().JSON()
+	default:
+		jsonData := make([]interface{}, length)
+		for i, err := range a {
+			jsonData[i] = err.JSON()
+		}
+		return jsonData
+	}
+}
+
+// MarshalJSON实现json.Marshaller接口。 MarshalJSON implements the json.Marshaller interface.
+func (a errorMsgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.JSON())
+}
+
+// Last返回列表中的最后一个错误。 Last returns the last error in the slice.
+// 如果数组为空，则返回nil。 It returns nil if the array is empty.
+func (a errorMsgs) Last() *Error {
+	if length := len(a); length > 0 {
+		return a[length-1]
+	}
+	return nil
+}
+
 func (a errorMsgs) String() string {
 	if len(a) == 0 {
 		return ""
@@ -67,4 +134,5 @@ func (a errorMsgs) String() string {
 			fmt.Fprintf(&buffer, "meta:%v\n", msg.Meta)
 		}
 	}
+	return buffer.String()
 }
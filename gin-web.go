@@ -1,16 +1,21 @@
 package gin_web
 
 import (
+	"context"
 	"fmt"
 	"github.com/sourcecmdb/gin-web/internal/bytesconv"
 	"github.com/sourcecmdb/gin-web/render"
 	"github.com/sourcecmdb/gin-web/utils"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"html/template"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"path"
 	"sync"
+	"time"
 )
 
 const defaultMultipartMemory = 32 << 20 // 32 m  内存
@@ -36,6 +41,8 @@ func (c HandlersChain) Last() HandlerFunc {
 
 // Routeelnfo 表示请求路由的规范 其中包含方法和路径及其处理程序
 type RouteInfo struct {
+	Name        string      // 路由名称，可为空 route name, may be empty
+	Host        string      // 主机模式，空表示后备树 host pattern, empty for the fallback tree
 	Method      string      // 方法
 	Path        string      // 路由路径
 	Handler     string      // Handler 请求头
@@ -75,7 +82,21 @@ type Engine struct {
 	//如果不允许其他方法，则将请求委托给NotFound   If no other Method is allowed, the request is delegated to the NotFound
 	//处理程序。   handler.
 	HandleMethodNotAllowed bool
-	ForwardedByClientIP    bool
+
+	//如果启用，当请求来自可信代理时，客户端IP从RemoteIPHeaders中解析。 If enabled, the client IP is parsed from RemoteIPHeaders
+	//参见SetTrustedProxies。 when the request comes from a trusted proxy, see SetTrustedProxies.
+	ForwardedByClientIP bool
+
+	//ForwardedByClientIP为true时按顺序检查的客户端IP头，只使用第一个存在的头。 RemoteIPHeaders are the client IP headers checked in order
+	//when ForwardedByClientIP is true, only the first present header is used.
+	//支持Forwarded（RFC 7239）、X-Forwarded-For样式的列表和单个IP头。 Forwarded (RFC 7239), X-Forwarded-For style lists and single IP headers are supported.
+	//Forwarded默认不启用：只有当代理覆盖或追加它时才添加，否则客户端可以伪造它。 Forwarded is not enabled by default:
+	//only add it when the proxies overwrite or append it, otherwise clients can spoof it.
+	RemoteIPHeaders []string
+
+	//如果设置，则信任该平台设置的包含客户端IP的头，例如PlatformCloudflare。 If set, the header holding the client IP set by that platform
+	//只有当平台保证覆盖该头时才设置它。 is trusted, e.g. PlatformCloudflare. Only set it when the platform guarantees to overwrite the header.
+	TrustedPlatform string
 
 	//＃726＃755如果启用，它将以 #726 #755 If enabled, it will thrust some headers starting with
 	//'X-AppEngine ...'，以更好地与该PaaS集成。 'X-AppEngine...' for better integration with that PaaS.
@@ -84,6 +105,12 @@ type Engine struct {
 	//如果启用，则将使用url.RawPath查找参数。  If enabled, the url.RawPath will be used to find parameters.
 	UseRawPath bool
 
+	//如果启用，Run、RunUnix和RunListener还会服务明文HTTP/2（h2c）， If enabled, Run, RunUnix and RunListener also serve cleartext HTTP/2 (h2c),
+	//包括预先知道（prior knowledge）和Upgrade两种方式。 both with prior knowledge and through Upgrade.
+	//h2c在ServerConfig.Configure之后应用，因此替换的处理程序也会被包装。 h2c is applied after ServerConfig.Configure,
+	//so a handler replaced there is wrapped too.
+	UseH2C bool
+
 	//如果为true，则路径值将不转义。  If true, the path value will be unescaped.
 	//如果UseRawPath为false（默认情况下），则UnescapePathValues实际上为true，  If UseRawPath is false (by default), the UnescapePathValues effectively is true,
 	//作为url.Path将被使用，它已经被转义了。  as url.Path gonna be used, which is already unescaped.
@@ -104,8 +131,25 @@ type Engine struct {
 	allNoRoute       HandlersChain
 	allNoMethod      HandlersChain
 	noRoute          HandlersChain
+	noMethod         HandlersChain
 	pool             sync.Pool
-	trees            methodTrees
+	swapper          routeSwapper
+
+	//优雅关闭时等待活动请求完成的最长时间，默认为30秒。 ShutdownTimeout is the maximum time to wait for active requests
+	//当传给RunContext的上下文结束时使用。 when the context given to RunContext is done, 30 seconds by default.
+	ShutdownTimeout time.Duration
+
+	//每个Run*变体构建的http.Server的超时和限制，New()设置为DefaultServerConfig() ServerConfig holds the timeouts and limits
+	//of the http.Server built by every Run* variant, New() sets it to DefaultServerConfig().
+	ServerConfig ServerConfig
+
+	//如果设置，Run*变体接受的连接会解析PROXY协议头，使RemoteAddr反映真实的客户端。 If set, the connections accepted by the Run* variants
+	//参见NewProxyProtocolListener。 parse the PROXY protocol header so that RemoteAddr reflects the real client, see NewProxyProtocolListener.
+	ProxyProtocol *ProxyProtocolConfig
+	lifecycle     lifecycle
+	upgrader      upgrader
+	trustedCIDRs  []*net.IPNet
+	logger        *slog.Logger
 }
 
 var _ IRouter = &Engine{}
@@ -116,6 +160,7 @@ var _ IRouter = &Engine{}
 // - RedirectFixedPath:      false
 // - HandleMethodNotAllowed: false
 // - ForwardedByClientIP:    true
+// - RemoteIPHeaders:        X-Forwarded-For, X-Real-IP
 // - UseRawPath:             false
 // - UnescapePathValues:     true
 func New() *Engine {
@@ -131,23 +176,32 @@ func New() *Engine {
 		RedirectFixedPath:      false,
 		HandleMethodNotAllowed: false,
 		ForwardedByClientIP:    true,
+		RemoteIPHeaders:        []string{"X-Forwarded-For", "X-Real-IP"},
 		AppEngine:              defaultAppEngin,
 		UseRawPath:             false,
 		RemoveExtraSlash:       false,
 		UnescapePathValues:     true,
 		MaxMultipartMemory:     defaultMultipartMemory,
-		trees:                  make(methodTrees, 0, 9),
+		ServerConfig:           DefaultServerConfig(),
 		delims:                 render.Delims{Left: "{{", Right: "}}"},
 		secureJsonPrefix:       "while(1)",
 	}
 	engine.RouterGroup.engine = engine
+	engine.swapper.table.Store(&routeTable{trees: make(methodTrees, 0, 9)})
+	engine.FuncMap[urlForFuncName] = engine.URLFor
 	engine.pool.New = func() interface{} {
 		return engine.allocateContext()
 	}
 	return engine
 }
-func (engine *Engine) Use(middleware ...HandlerFunc) IRoutes {
 
+// Use将全局中间件附加到路由器。 即通过Use（）附加的中间件将是 Use attaches a global middleware to the router. ie. the middleware attached though Use() will be
+// 包含在每个单个请求的处理程序链中。 甚至404、405，静态文件... included in the handlers chain for every single request. Even 404, 405, static files...
+func (engine *Engine) Use(middleware ...HandlerFunc) IRoutes {
+	engine.RouterGroup.Use(middleware...)
+	engine.rebuild404Handlers()
+	engine.rebuild405Handlers()
+	return engine
 }
 
 // 默认值返回已连接Logger和Recovery中间件的Engine实例。Default returns an Engine instance with the Logger and Recovery middleware already attached.=
@@ -176,7 +230,7 @@ func (engine *Engine) SecureJsonPrefix(prefix string) *Engine {
 
 // SetHTMLTemplate将模板与HTML渲染器关联。 // SetHTMLTemplate associate a template with HTML renderer.
 func (engine *Engine) SetHTMLTemplate(templ *template.Template) {
-	if len(engine.trees) > 0 {
+	if len(engine.routes().trees) > 0 {
 		debugPrintWARNIGSetHTMLTemplate()
 	}
 	engine.HTMLRender = render.HTMLProduction{Template: templ.Funcs(engine.FuncMap)}
@@ -209,8 +263,15 @@ func (engine *Engine) LoadHTMLFiles(files ...string) {
 }
 
 // SetFuncMap设置用于template.FuncMap的FuncMap。 // SetFuncMap sets the FuncMap used for template.FuncMap.
+// funcMap会被复制，调用方的map不会被修改；如果其中没有urlFor，则会添加Engine.URLFor。
+// funcMap is copied so the caller's map is never modified; Engine.URLFor is added as urlFor unless funcMap already has one.
 func (engine *Engine) SetFuncMap(funcMap template.FuncMap) {
-	engine.FuncMap = funcMap
+	fm := make(template.FuncMap, len(funcMap)+1)
+	fm[urlForFuncName] = engine.URLFor
+	for name, fn := range funcMap {
+		fm[name] = fn
+	}
+	engine.FuncMap = fm
 }
 
 func (engine *Engine) rebuild404Handlers() {
@@ -218,7 +279,7 @@ func (engine *Engine) rebuild404Handlers() {
 }
 
 func (engine *Engine) rebuild405Handlers() {
-	engine.allNoMethod = engine.combineHandlers(engine.allNoMethod)
+	engine.allNoMethod = engine.combineHandlers(engine.noMethod)
 }
 
 // NoRoute为NoRoute添加处理程序。 默认情况下，它返回404代码。// NoRoute adds handlers for NoRoute. It return a 404 code by default.
@@ -227,26 +288,38 @@ func (engine *Engine) NoRoute(handlers ...HandlerFunc) {
 	engine.rebuild404Handlers()
 }
 
-func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
+// NoMethod设置在Engine.HandleMethodNotAllowed = true时调用的处理程序。 // NoMethod sets the handlers called when Engine.HandleMethodNotAllowed = true.
+func (engine *Engine) NoMethod(handlers ...HandlerFunc) {
+	engine.noMethod = handlers
+	engine.rebuild405Handlers()
+}
+
+// addRoute 将路由添加到给定主机模式的树中，host为空时添加到后备树。 addRoute adds a route to the trees of the given host pattern, or to the fallback trees if host is empty.
+// 它就地修改当前的路由快照，因此只能在初始化时调用；运行时请使用AddRoute。 It changes the current route snapshot in place,
+// so it must only be called at initialization; use AddRoute at runtime.
+func (engine *Engine) addRoute(host, method, path string, handlers HandlersChain) {
 	utils.Assert1(path[0] == '/', "path must begin with '/' ")
 	utils.Assert1(method != "", "HTTP method can not be empty")
 	utils.Assert1(len(handlers) > 0, "There must be at least one handler")
 
-	debugPrintRoute(method, path, handlers)
-	root := engine.trees.get(method)
+	debugPrintRoute(engine.logger, method, host+path, handlers)
+	trees := engine.routes().methodTrees(host)
+	root := trees.get(method)
 	if root == nil {
 		root = new(node)
 		root.fullPath = "/"
-		engine.trees = append(engine.trees, methodTree{method: method, root: root})
+		*trees = append(*trees, methodTree{method: method, root: root})
 	}
 	root.addRoute(path, handlers)
 }
 
-func iterate(path, method string, routes Routesinfo, root *node) Routesinfo {
+func iterate(table *routeTable, host, path, method string, routes Routesinfo, root *node) Routesinfo {
 	path += root.path
 	if len(root.handlers) > 0 {
 		handlerFunc := root.handlers.Last()
 		routes = append(routes, RouteInfo{
+			Name:        table.routeName(host, method, path),
+			Host:        host,
 			Method:      method,
 			Path:        path,
 			Handler:     utils.NameOfFunction(handlerFunc),
@@ -254,7 +327,7 @@ func iterate(path, method string, routes Routesinfo, root *node) Routesinfo {
 		})
 	}
 	for _, child := range root.children {
-		routes = iterate(path, method, routes, child)
+		routes = iterate(table, host, path, method, routes, child)
 	}
 	return routes
 }
@@ -262,48 +335,54 @@ func iterate(path, method string, routes Routesinfo, root *node) Routesinfo {
 // 路线返回一部分已注册的路线，其中包括一些有用的信息，例如： Routes returns a slice of registered routes including some userful information such as:
 // http方法，路径和处理程序名称。 the http method , path and the handler name.
 func (engine *Engine) Routes() (routes Routesinfo) {
-	for _, tree := range engine.trees {
-		routes = iterate("", tree.method, routes, tree.root)
+	table := engine.routes()
+	for _, tree := range table.trees {
+		routes = iterate(table, "", "", tree.method, routes, tree.root)
+	}
+	for _, h := range table.hosts {
+		for _, tree := range h.trees {
+			routes = iterate(table, h.pattern, "", tree.method, routes, tree.root)
+		}
 	}
 	return routes
 }
 
 //运行将路由器附加到http.Server并开始侦听和处理HTTP请求 // Run attaches the router to a http.Server and starts listening and serving HTTP requests
-//这是http.ListenAndSeve（addr，router）的快捷方式 // It is a shortcut for http.ListenAndSeve(addr,router)
-//注意：除非发生错误，否则此方法将无限期阻止调用goroutine // Note: this method will block the calling goroutine indefinitely unless an error happens
+//它是RunContext(context.Background(), addr...)的快捷方式，使用Shutdown优雅地停止 // It is a shortcut for RunContext(context.Background(), addr...), use Shutdown to stop it gracefully
+//注意：除非发生错误或调用Shutdown，否则此方法将无限期阻止调用goroutine // Note: this method will block the calling goroutine indefinitely unless an error happens or Shutdown is called
 func (engine *Engine) Run(addr ...string) (err error) {
-	defer func() { debugPrintError(err) }()
-
-	address := utils.ResolverAddress(addr)
-	DebugPrint("监听并提供HTTP服务 Listening and serving HTTP on %s\n", address)
-	err = http.ListenAndServe(address, engine)
-	return
+	return engine.RunContext(context.Background(), addr...)
 }
 
 // RunTLS将路由器附加到http.Server，并开始侦听和处理HTTPS（安全）请求。 // RunTLS attaches the router to a http.Server and starts listening and serving HTTPS (secure) requests.
 //这是http.ListenAndServeTLS（addr，certFile，keyFile，router）的快捷方式 // It is a shortcut for http.ListenAndServeTLS(addr, certFile, keyFile, router)
-//注意：除非发生错误，否则此方法将无限期阻止调用goroutine。 // Note: this method will block the calling goroutine indefinitely unless an error happens.
+//注意：除非发生错误或调用Shutdown，否则此方法将无限期阻止调用goroutine。 // Note: this method will block the calling goroutine indefinitely unless an error happens or Shutdown is called.
 func (engine *Engine) RunTLS(addr, certFile, keyFile string) (err error) {
 	DebugPrint("监听并提供HTTPS服务 Listening and serving HTTPS on %s\n", addr)
 	defer func() { debugPrintError(err) }()
-	err = http.ListenAndServeTLS(addr, certFile, keyFile, engine)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return
+	}
+	err = engine.serve(context.Background(), listener, func(srv *http.Server, ln net.Listener) error {
+		return srv.ServeTLS(ln, certFile, keyFile)
+	})
 	return
 }
 
 // RunUnix将路由器附加到http.Server并开始侦听和处理HTTP请求 // RunUnix attaches the router to a http.Server and starts listening and serving HTTP requests
 //通过指定的Unix套接字（即文件）。 // through the specified unix socket (ie. a file).
-//注意：除非发生错误，否则此方法将无限期阻止调用goroutine。// Note: this method will block the calling goroutine indefinitely unless an error happens.
+//注意：除非发生错误或调用Shutdown，否则此方法将无限期阻止调用goroutine。// Note: this method will block the calling goroutine indefinitely unless an error happens or Shutdown is called.
 func (engine *Engine) RunUnix(file string) (err error) {
 	DebugPrint("监听并提供HTTP服务 Listening and serving  HTTP on unix:/%s", file)
 	defer func() { debugPrintError(err) }()
-	listener, err := net.Listen("nuix", file)
+	listener, err := net.Listen("unix", file)
 	if err != nil {
 		return
 	}
-	defer listener.Close()
 	defer os.Remove(file)
 
-	err = http.Serve(listener, engine)
+	err = engine.serve(context.Background(), listener, serveHTTP)
 	return
 }
 
@@ -312,13 +391,13 @@ func (engine *Engine) RunUnix(file string) (err error) {
 func (engine *Engine) RunListener(listener net.Listener) (err error) {
 	DebugPrint("在侦听器上侦听和服务HTTP与address @％s绑定的内容 Listening and serving HTTP on listener what's bind with address@%s", listener.Addr())
 	defer func() { debugPrintError(err) }()
-	err = http.Serve(listener, engine)
+	err = engine.serve(context.Background(), listener, serveHTTP)
 	return
 }
 
 // RunFd将路由器附加到http.Server并开始侦听和处理HTTP请求 // RunFd attaches the router to a http.Server and starts listening and serving HTTP requests
 //通过指定的文件描述符。  // through the specified file descriptor.
-//注意：除非发生错误，否则此方法将无限期阻止调用goroutine。 // Note: this method will block the calling goroutine indefinitely unless an error happens.
+//注意：除非发生错误或调用Shutdown，否则此方法将无限期阻止调用goroutine。 // Note: this method will block the calling goroutine indefinitely unless an error happens or Shutdown is called.
 func (engine *Engine) RunFd(fd int) (err error) {
 	DebugPrint("Listening and serving HTTP on fd@%d", fd)
 	defer func() { debugPrintError(err) }()
@@ -328,7 +407,6 @@ func (engine *Engine) RunFd(fd int) (err error) {
 	if err != nil {
 		return
 	}
-	defer listerner.Close()
 	err = engine.RunListener(listerner)
 	return
 }
@@ -402,8 +480,9 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 	if engine.RemoveExtraSlash {
 		rPath = cleanPath(rPath)
 	}
+	//先按主机选择树，主机通配符会被保存到参数中 // pick the trees by host first, host wildcards are saved into the params
+	t, params := engine.routes().treesForHost(c.Request.Host, c.Params)
 	//查找给定HTTP方法的reee的根 // find root of the reee for the given HTTP method
-	t := engine.trees
 	for i, tl := 0, len(t); i < tl; i++ {
 		if t[i].method != httpMethod {
 			continue
@@ -411,7 +490,7 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 
 		root := t[i].root
 		//在树中查找路线 // Find route in  tree
-		value := root.getValue(rPath, c.Params, unescape)
+		value := root.getValue(rPath, params, unescape)
 		if value.handlers != nil {
 			c.handlers = value.handlers
 			c.Params = value.params
@@ -433,7 +512,7 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		break
 	}
 	if engine.HandleMethodNotAllowed {
-		for _, tree := range engine.trees {
+		for _, tree := range t {
 			if tree.method == httpMethod {
 				continue
 			}
@@ -448,6 +527,40 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 	serveError(c, http.StatusNotFound, default404Body)
 }
 
+// Handler 返回为引擎服务的http.Handler，启用UseH2C时它还服务明文HTTP/2。 // Handler returns the http.Handler serving the engine,
+// it also serves cleartext HTTP/2 when UseH2C is enabled.
+// HTTP/2的超时取自Engine.ServerConfig，但这些连接不参与优雅关闭；自行管理服务器时请优先使用NewServer。
+// The HTTP/2 timeouts are taken from Engine.ServerConfig but those connections take no part in graceful shutdown,
+// prefer NewServer when managing the server yourself.
+func (engine *Engine) Handler() http.Handler {
+	if !engine.UseH2C {
+		return engine
+	}
+	config := engine.ServerConfig
+	h2s := &http2.Server{
+		IdleTimeout:          positiveDuration(config.IdleTimeout),
+		MaxConcurrentStreams: config.MaxConcurrentStreams,
+	}
+	if h2s.IdleTimeout == 0 {
+		h2s.IdleTimeout = positiveDuration(config.ReadTimeout)
+	}
+	return h2c.NewHandler(engine, h2s)
+}
+
+// h2cHandler 包装srv的处理程序以服务明文HTTP/2，HTTP/2设置取自srv， h2cHandler wraps the handler of srv to serve cleartext HTTP/2
+// with settings taken from srv, so that its idle timeout applies and Shutdown also closes the HTTP/2 connections gracefully.
+func h2cHandler(srv *http.Server, config ServerConfig) http.Handler {
+	handler := srv.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	h2s := &http2.Server{MaxConcurrentStreams: config.MaxConcurrentStreams}
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		debugPrintError(err)
+	}
+	return h2c.NewHandler(handler, h2s)
+}
+
 // ServeHTTP符合http.Handler接口。 // ServeHTTP conforms to the http.Handler interface.
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	c := engine.pool.Get().(*Context)
@@ -455,5 +568,6 @@ func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	c.Request = req
 	c.reset()
 	engine.handleHTTPRequest(c)
+	c.release()
 	engine.pool.Put(c)
 }
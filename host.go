@@ -0,0 +1,140 @@
+package gin_web
+
+import (
+	"net"
+	"strings"
+)
+
+// hostTree 保存某个主机模式下注册的路由树 hostTree holds the route trees registered for one host pattern.
+type hostTree struct {
+	pattern string
+	labels  []string
+	wild    bool
+	trees   methodTrees
+}
+
+// hostTrees 按注册顺序保存所有主机树 hostTrees holds every host tree in registration order.
+type hostTrees []*hostTree
+
+// get 返回给定模式的主机树，如果不存在则返回nil get returns the host tree of the given pattern, or nil if there is none.
+func (hosts hostTrees) get(pattern string) *hostTree {
+	for _, h := range hosts {
+		if h.pattern == pattern {
+			return h
+		}
+	}
+	return nil
+}
+
+// remove 返回去掉给定模式的主机树后的列表 remove returns the list without the host tree of the given pattern.
+func (hosts hostTrees) remove(pattern string) hostTrees {
+	for i, h := range hosts {
+		if h.pattern == pattern {
+			return append(hosts[:i:i], hosts[i+1:]...)
+		}
+	}
+	return hosts
+}
+
+// newHostTree 解析诸如 :tenant.example.com 的主机模式 newHostTree parses a host pattern such as :tenant.example.com
+func newHostTree(pattern string) *hostTree {
+	h := &hostTree{pattern: pattern, labels: strings.Split(pattern, ".")}
+	for _, label := range h.labels {
+		if label == "" {
+			panic("主机模式中不能有空标签 empty label in host pattern '" + pattern + "'")
+		}
+		if label[0] == ':' {
+			if len(label) < 2 {
+				panic("通配符必须在主机模式中使用非空名称命名 wildcards must be named with a non-empty name in host pattern '" + pattern + "'")
+			}
+			h.wild = true
+		}
+	}
+	return h
+}
+
+// match 报告host是否匹配该模式，并将通配符标签追加到params。 match reports whether host matches the pattern, appending wildcard labels to params.
+func (h *hostTree) match(host string, params Params) (Params, bool) {
+	if !h.wild {
+		return params, host == h.pattern
+	}
+	if strings.Count(host, ".")+1 != len(h.labels) {
+		return params, false
+	}
+	start := len(params)
+	for _, label := range h.labels {
+		end := strings.IndexByte(host, '.')
+		if end < 0 {
+			end = len(host)
+		}
+		value := host[:end]
+		if label[0] == ':' {
+			if value == "" {
+				return params[:start], false
+			}
+			params = append(params, Param{Key: label[1:], Value: value})
+		} else if label != value {
+			return params[:start], false
+		}
+		if end < len(host) {
+			host = host[end+1:]
+		}
+	}
+	return params, true
+}
+
+// normalizeHost 去掉端口和结尾的点，并转换为小写 normalizeHost strips the port and the trailing dot and lowercases the host.
+// 只去掉数字端口，因此":tenant.example.com"这样的模式保持不变。 Only a numeric port is stripped so that patterns like ":tenant.example.com" are kept.
+func normalizeHost(host string) string {
+	if h, port, err := net.SplitHostPort(host); err == nil && isPort(port) {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// hostTree 返回给定主机模式的树，必要时创建它 hostTree returns the tree of the given host pattern, creating it if necessary.
+func (t *routeTable) hostTree(pattern string) *hostTree {
+	if h := t.hosts.get(pattern); h != nil {
+		return h
+	}
+	h := newHostTree(pattern)
+	t.hosts = append(t.hosts, h)
+	return h
+}
+
+// treesForHost 返回应为给定请求主机搜索的方法树。 treesForHost returns the method trees that should be searched for the given request host.
+// 精确的主机模式优先于通配符模式，通配符模式按注册顺序尝试； Exact host patterns win over wildcard ones, which are tried in registration order;
+// 如果没有主机匹配，则返回不带主机的后备树。 the fallback trees without a host are returned if no host matches.
+func (t *routeTable) treesForHost(host string, params Params) (methodTrees, Params) {
+	if len(t.hosts) == 0 {
+		return t.trees, params
+	}
+	host = normalizeHost(host)
+	for _, h := range t.hosts {
+		if !h.wild && h.pattern == host {
+			return h.trees, params
+		}
+	}
+	for _, h := range t.hosts {
+		if !h.wild {
+			continue
+		}
+		if ps, ok := h.match(host, params); ok {
+			return h.trees, ps
+		}
+	}
+	return t.trees, params
+}
+
+// isPort 报告p是否是数字端口 isPort reports whether p is a numeric port.
+func isPort(p string) bool {
+	if p == "" {
+		return false
+	}
+	for i := 0; i < len(p); i++ {
+		if p[i] < '0' || p[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package gin_web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Example.COM", "example.com"},
+		{"example.com:8080", "example.com"},
+		{"example.com.", "example.com"},
+		{"[::1]:80", "::1"},
+		{":tenant.example.com", ":tenant.example.com"},
+	}
+	for _, tt := range tests {
+		if got := normalizeHost(tt.in); got != tt.want {
+			t.Errorf("normalizeHost(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, "default") })
+	router.Host("api.example.com").GET("/", func(c *Context) { c.String(http.StatusOK, "api") })
+	router.Host(":tenant.example.com").GET("/", func(c *Context) { c.String(http.StatusOK, "tenant "+c.Param("tenant")) })
+
+	tests := []struct {
+		host, body string
+	}{
+		{"api.example.com", "api"},
+		{"API.example.com:8080", "api"},
+		{"acme.example.com", "tenant acme"},
+		{"a.b.example.com", "default"},
+		{"other.org", "default"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Body.String() != tt.body {
+			t.Errorf("host %q served %q, want %q", tt.host, w.Body.String(), tt.body)
+		}
+	}
+}
@@ -55,7 +55,11 @@ type LogFormatterParam struct {
 	// BodySize是响应主体的大小 // BodySize is the size of the Response Body
 	BodySize int
 	// 密钥是在请求上下文中设置的密钥。 //Keys are the keys set on the request's context.
-	keys map[string]interface{}
+	Keys map[string]interface{}
+	// ResponseHeader是已写入的响应标头。 // ResponseHeader is the written response header.
+	ResponseHeader http.Header
+	// RequestID是从LoggerConfig.RequestIDHeader读取的请求ID。 // RequestID is the request ID read from LoggerConfig.RequestIDHeader.
+	RequestID string
 }
 
 // LoggerConfig定义Logger中间件的配置。  LoggerConfig defines the config for Logger middleware.
@@ -68,8 +72,31 @@ type LoggerConfig struct {
 	// SkipPaths是未写入日志的网址路径数组。	// SkipPaths is a url path array which logs are not written.
 	// 可选的。	// Optional.
 	SkipPaths []string
+	// RequestIDHeader是保存请求ID的标头，先在响应中查找，然后在请求中查找。 // RequestIDHeader is the header holding the request ID,
+	// looked up in the response first, then in the request.
+	// 可选的。 默认值为X-Request-ID。 // Optional. Default value is X-Request-ID.
+	RequestIDHeader string
+	// Skip在请求处理后调用，返回true时不写入日志。 // Skip is called once the request is handled, no log is written when it returns true.
+	// 可选的。	// Optional.
+	Skip Skipper
+	// SkipPathPatterns是未写入日志的path.Match路径模式，以"/**"结尾的模式匹配该前缀下的所有路径。 // SkipPathPatterns are path.Match
+	// patterns of the paths which logs are not written, a pattern ending with "/**" matches every path under that prefix.
+	// 可选的。	// Optional.
+	SkipPathPatterns []string
+	// SampleRates是按状态码记录的请求比例（0到1），键是确切的状态码或状态类别1到5， // SampleRates is the ratio (0 to 1) of the requests logged
+	// 确切的状态码优先。没有比例的状态总是被记录。 // per status, keys are exact status codes or status classes 1 to 5, exact codes win.
+	// Statuses without a rate are always logged, e.g. {2: 0.01} logs 1% of 2xx and every error.
+	// 可选的。	// Optional.
+	SampleRates map[int]float64
+	// SlowThreshold以上的请求和带有c.Errors的请求总是被记录，忽略Skip、跳过的路径和采样。 // Requests slower than SlowThreshold
+	// and requests with c.Errors are always logged, regardless of Skip, the skipped paths and sampling.
+	// 可选的。	// Optional.
+	SlowThreshold time.Duration
 }
 
+// Skipper是一个根据提供的上下文跳过日志的函数。 // Skipper is a function to skip logs based on the provided Context.
+type Skipper func(c *Context) bool
+
 // IsOutputColor指示是否可以将颜色输出到日志。  IsOutputColor indicates whether can colors be outputted to the log.
 func (p *LogFormatterParam) IsOutputColor() bool {
 	return consoleColorMode == forceColor || (consoleColorMode == autoColor && p.isTerm)
@@ -143,7 +170,10 @@ var defaultLogFormatter = func(param LogFormatterParam) string {
 }
 
 // LoggerWithConfig实例具有配置的Logger中间件。 LoggerWithConfig instance a Logger middleware with config.
+// 如果引擎设置了slog日志记录器，并且既没有设置Formatter也没有设置Output， If the engine has a slog logger and neither Formatter nor Output is set,
+// 则访问日志以与状态码匹配的级别写入它。 the access log is written to it at the level matching the status code.
 func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
+	useSlog := conf.Formatter == nil && conf.Output == nil
 	formatter := conf.Formatter
 	if formatter == nil {
 		formatter = defaultLogFormatter
@@ -153,10 +183,14 @@ func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
 		out = DefaultWriter
 	}
 	notlogged := conf.SkipPaths
+	requestIDHeader := conf.RequestIDHeader
+	if requestIDHeader == "" {
+		requestIDHeader = "X-Request-ID"
+	}
 
 	isTerm := true
 
-	if w, ok := out.(*os.File); !ok || os.Getenv("TERM") == "dumb" || (go_isatty.IsTerminal(w.Fd()) && go_isatty.IsCygwinTerminal(w.Fd())) {
+	if w, ok := out.(*os.File); !ok || os.Getenv("TERM") == "dumb" || (!go_isatty.IsTerminal(w.Fd()) && !go_isatty.IsCygwinTerminal(w.Fd())) {
 		isTerm = false
 	}
 
@@ -169,6 +203,7 @@ func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
 			skip[path] = struct{}{}
 		}
 	}
+	patterns := compileSkipPatterns(conf.SkipPathPatterns)
 
 	return func(c *Context) {
 		// 开始时间 Start timer
@@ -179,16 +214,22 @@ func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
 		// 流程要求 Process request
 		c.Next()
 
-		// 仅在不跳过路径时记录 Log only when path is not being skipped
-		if _, ok := skip[path]; !ok {
+		// 停止时间  stop timer
+		timeStamp := time.Now()
+		latency := timeStamp.Sub(start)
+
+		// 仅在不跳过请求时记录，慢请求和出错的请求总是被记录 Log only when the request is not being skipped, slow and failed requests are always logged
+		_, skipped := skip[path]
+		skipped = skipped || patterns.match(path) || (conf.Skip != nil && conf.Skip(c)) || !sampled(conf.SampleRates, c.Writer.Status())
+		always := len(c.Errors) > 0 || (conf.SlowThreshold > 0 && latency >= conf.SlowThreshold)
+		if !skipped || always {
 			param := LogFormatterParam{
-				Request: c.Request,
-				isTerm:  isTerm,
-				keys:    c.Keys,
+				Request:   c.Request,
+				isTerm:    isTerm,
+				Keys:      c.Keys,
+				TimeStamp: timeStamp,
+				Latency:   latency,
 			}
-			// 停止时间  stop timer
-			param.TimeStamp = time.Now()
-			param.Latency = param.TimeStamp.Sub(start)
 
 			param.ClientIP = c.ClientIP()
 			param.Method = c.Request.Method
@@ -196,12 +237,20 @@ func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
 			param.ErrorMessge = c.Errors.ByType(ErrorTypePrivate).String()
 
 			param.BodySize = c.Writer.Size()
+			param.ResponseHeader = c.Writer.Header()
+			if param.RequestID = param.ResponseHeader.Get(requestIDHeader); param.RequestID == "" {
+				param.RequestID = c.Request.Header.Get(requestIDHeader)
+			}
 
 			if raw != "" {
 				path = path + "?" + raw
 			}
 			param.Path = path
 
+			if logger := c.engine.logger; useSlog && logger != nil {
+				slogAccess(logger, param)
+				return
+			}
 			fmt.Fprint(out, formatter(param))
 		}
 	}
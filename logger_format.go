@@ -0,0 +1,246 @@
+package gin_web
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CommonLogFormat 是Apache/Nginx通用日志格式 CommonLogFormat is the Apache/Nginx Common Log Format.
+	CommonLogFormat = `%h %l %u %t "%r" %>s %b`
+	// CombinedLogFormat 是Apache/Nginx组合日志格式 CombinedLogFormat is the Apache/Nginx Combined Log Format.
+	CombinedLogFormat = CommonLogFormat + ` "%{Referer}i" "%{User-Agent}i"`
+)
+
+var (
+	// CommonLogFormatter 以通用日志格式写入访问日志 CommonLogFormatter writes the access log in the Common Log Format.
+	CommonLogFormatter = MustCompileLogFormat(CommonLogFormat)
+	// CombinedLogFormatter 以组合日志格式写入访问日志 CombinedLogFormatter writes the access log in the Combined Log Format.
+	CombinedLogFormatter = MustCompileLogFormat(CombinedLogFormat)
+)
+
+// logSegment 将日志格式的一部分附加到buf logSegment appends one part of a log format to buf.
+type logSegment func(buf []byte, param *LogFormatterParam) []byte
+
+// CompileLogFormat 将Apache风格的格式模板编译为LogFormatter。 CompileLogFormat compiles an Apache style format template into a LogFormatter.
+// 支持的指令 Supported directives:
+//
+//	%h %a      客户端IP client IP
+//	%l         总是 always "-"
+//	%u         基本认证用户 basic auth user
+//	%t         时间 time, [02/Jan/2006:15:04:05 -0700]
+//	%r         请求行 request line
+//	%s %>s     状态码 status code
+//	%b         响应大小，0为"-" body size, "-" for 0
+//	%B         响应大小 body size
+//	%D         延迟（微秒） latency in microseconds
+//	%T         延迟（秒） latency in seconds
+//	%m         方法 method
+//	%U         不带查询的路径 path without query
+//	%q         查询字符串 query string
+//	%H         协议 protocol
+//	%L         请求ID request ID
+//	%{Name}i   请求头 request header
+//	%{Name}o   响应头 response header
+//	%%         字面的 literal %
+//
+// 值中的引号、反斜杠和控制字符会被转义，因此日志中不会出现ANSI代码。 Quotes, backslashes and control characters in values are escaped
+// so that no ANSI code reaches the log.
+func CompileLogFormat(format string) (LogFormatter, error) {
+	var segments []logSegment
+	literal := strings.Builder{}
+	flush := func() {
+		if literal.Len() > 0 {
+			text := literal.String()
+			segments = append(segments, func(buf []byte, _ *LogFormatterParam) []byte {
+				return append(buf, text...)
+			})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			literal.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i == len(format) {
+			return nil, fmt.Errorf("日志格式以'%%'结尾 log format %q ends with '%%'", format)
+		}
+		var arg string
+		if format[i] == '{' {
+			end := strings.IndexByte(format[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("日志格式中未闭合的'{' unclosed '{' in log format %q", format)
+			}
+			arg = format[i+1 : i+end]
+			i += end + 1
+			if i == len(format) {
+				return nil, fmt.Errorf("日志格式中'%%{%s}'后缺少指令 missing directive after '%%{%s}' in log format %q", arg, arg, format)
+			}
+		} else if format[i] == '>' && i+1 < len(format) {
+			//%>s 是最终状态，与%s相同 // %>s is the final status, the same as %s
+			i++
+			if format[i] != 's' {
+				return nil, fmt.Errorf("日志格式中的'%%>'只能用于%%>s '%%>' is only valid in %%>s in log format %q", format)
+			}
+		}
+		if format[i] == '%' {
+			if arg != "" {
+				return nil, fmt.Errorf("'%%%%'不接受参数 '%%%%' does not take an argument, got '%%{%s}%%' in log format %q", arg, format)
+			}
+			literal.WriteByte('%')
+			continue
+		}
+		segment, err := logDirective(format[i], arg)
+		if err != nil {
+			return nil, err
+		}
+		flush()
+		segments = append(segments, segment)
+	}
+	flush()
+
+	return func(param LogFormatterParam) string {
+		buf := make([]byte, 0, 128)
+		for _, segment := range segments {
+			buf = segment(buf, &param)
+		}
+		return string(append(buf, '\n'))
+	}, nil
+}
+
+// MustCompileLogFormat 与CompileLogFormat类似，但是在格式无效时会惊慌 MustCompileLogFormat is like CompileLogFormat but panics if the format is invalid.
+func MustCompileLogFormat(format string) LogFormatter {
+	formatter, err := CompileLogFormat(format)
+	if err != nil {
+		panic(err)
+	}
+	return formatter
+}
+
+func logDirective(directive byte, arg string) (logSegment, error) {
+	if arg != "" && directive != 'i' && directive != 'o' {
+		return nil, fmt.Errorf("指令不接受参数 directive %%%c does not take an argument", directive)
+	}
+	switch directive {
+	case 'h', 'a':
+		return func(buf []byte, p *LogFormatterParam) []byte { return appendLogValue(buf, p.ClientIP) }, nil
+	case 'l':
+		return func(buf []byte, _ *LogFormatterParam) []byte { return append(buf, '-') }, nil
+	case 'u':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			user := ""
+			if p.Request != nil {
+				user, _, _ = p.Request.BasicAuth()
+			}
+			return appendLogValue(buf, user)
+		}, nil
+	case 't':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			buf = append(buf, '[')
+			buf = p.TimeStamp.AppendFormat(buf, "02/Jan/2006:15:04:05 -0700")
+			return append(buf, ']')
+		}, nil
+	case 'r':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			if p.Request == nil {
+				return append(buf, '-')
+			}
+			buf = appendLogValue(buf, p.Request.Method)
+			buf = append(buf, ' ')
+			buf = appendLogValue(buf, p.Request.URL.RequestURI())
+			buf = append(buf, ' ')
+			return appendLogValue(buf, p.Request.Proto)
+		}, nil
+	case 's':
+		return func(buf []byte, p *LogFormatterParam) []byte { return strconv.AppendInt(buf, int64(p.StatusCode), 10) }, nil
+	case 'b':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			if p.BodySize <= 0 {
+				return append(buf, '-')
+			}
+			return strconv.AppendInt(buf, int64(p.BodySize), 10)
+		}, nil
+	case 'B':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			size := p.BodySize
+			if size < 0 {
+				size = 0
+			}
+			return strconv.AppendInt(buf, int64(size), 10)
+		}, nil
+	case 'D':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			return strconv.AppendInt(buf, int64(p.Latency/time.Microsecond), 10)
+		}, nil
+	case 'T':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			return strconv.AppendInt(buf, int64(p.Latency/time.Second), 10)
+		}, nil
+	case 'm':
+		return func(buf []byte, p *LogFormatterParam) []byte { return appendLogValue(buf, p.Method) }, nil
+	case 'U':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			if p.Request == nil {
+				return append(buf, '-')
+			}
+			return appendLogValue(buf, p.Request.URL.Path)
+		}, nil
+	case 'q':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			if p.Request == nil || p.Request.URL.RawQuery == "" {
+				return buf
+			}
+			buf = append(buf, '?')
+			return appendLogValue(buf, p.Request.URL.RawQuery)
+		}, nil
+	case 'H':
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			if p.Request == nil {
+				return append(buf, '-')
+			}
+			return appendLogValue(buf, p.Request.Proto)
+		}, nil
+	case 'L':
+		return func(buf []byte, p *LogFormatterParam) []byte { return appendLogValue(buf, p.RequestID) }, nil
+	case 'i':
+		if arg == "" {
+			return nil, fmt.Errorf("指令%%i需要标头名称 directive %%i requires a header name, e.g. %%{User-Agent}i")
+		}
+		return func(buf []byte, p *LogFormatterParam) []byte {
+			if p.Request == nil {
+				return append(buf, '-')
+			}
+			return appendLogValue(buf, p.Request.Header.Get(arg))
+		}, nil
+	case 'o':
+		if arg == "" {
+			return nil, fmt.Errorf("指令%%o需要标头名称 directive %%o requires a header name, e.g. %%{Content-Type}o")
+		}
+		return func(buf []byte, p *LogFormatterParam) []byte { return appendLogValue(buf, p.ResponseHeader.Get(arg)) }, nil
+	}
+	return nil, fmt.Errorf("未知的日志指令 unknown log directive %%%c", directive)
+}
+
+// appendLogValue 附加转义后的值，空值为"-" appendLogValue appends the escaped value, "-" for an empty one.
+func appendLogValue(buf []byte, value string) []byte {
+	if value == "" {
+		return append(buf, '-')
+	}
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '"' || c == '\\':
+			buf = append(buf, '\\', c)
+		case c < 0x20 || c == 0x7f:
+			buf = append(buf, '\\', 'x', hex[c>>4], hex[c&0xf])
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
@@ -0,0 +1,95 @@
+package gin_web
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompileLogFormat(t *testing.T) {
+	req := httptest.NewRequest("GET", "/items?id=1", nil)
+	req.SetBasicAuth("bob", "secret")
+	req.Header.Set("User-Agent", "evil\x1b[31m\"agent\\")
+	req.Header.Set("Referer", "http://example.com/")
+	param := LogFormatterParam{
+		Request:        req,
+		TimeStamp:      time.Date(2020, time.March, 4, 5, 6, 7, 0, time.FixedZone("", 3600)),
+		StatusCode:     http.StatusCreated,
+		Latency:        1500 * time.Millisecond,
+		ClientIP:       "192.0.2.1",
+		Method:         "GET",
+		BodySize:       42,
+		ResponseHeader: http.Header{"Content-Type": {"text/plain"}},
+		RequestID:      "rid-1",
+	}
+	empty := param
+	empty.Request, empty.BodySize, empty.RequestID = nil, 0, ""
+
+	tests := []struct {
+		format string
+		param  LogFormatterParam
+		want   string
+	}{
+		{CommonLogFormat, param, `192.0.2.1 - bob [04/Mar/2020:05:06:07 +0100] "GET /items?id=1 HTTP/1.1" 201 42`},
+		{CombinedLogFormat, param, `192.0.2.1 - bob [04/Mar/2020:05:06:07 +0100] "GET /items?id=1 HTTP/1.1" 201 42 "http://example.com/" "evil\x1b[31m\"agent\\"`},
+		{"%a %m %U%q %H %s %>s", param, "192.0.2.1 GET /items?id=1 HTTP/1.1 201 201"},
+		{"%b %B %D %T %L", param, "42 42 1500000 1 rid-1"},
+		{"%b %B %L %u %r %U %H %{User-Agent}i", empty, "- 0 - - - - - -"},
+		{"%{Content-Type}o %{X-Missing}o", param, "text/plain -"},
+		{"100%% done", param, "100% done"},
+		{"[%m]", param, "[GET]"},
+	}
+	for _, tt := range tests {
+		formatter, err := CompileLogFormat(tt.format)
+		if err != nil {
+			t.Errorf("CompileLogFormat(%q): %v", tt.format, err)
+			continue
+		}
+		if got := formatter(tt.param); got != tt.want+"\n" {
+			t.Errorf("CompileLogFormat(%q) wrote %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestCompileLogFormatErrors(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr string
+	}{
+		{"%", "ends with '%'"},
+		{"%{User-Agent", "unclosed '{'"},
+		{"%{User-Agent}", "missing directive"},
+		{"%z", "unknown log directive %z"},
+		{"%i", "requires a header name"},
+		{"%o", "requires a header name"},
+		{"%{X}s", "does not take an argument"},
+		{"%{X}%", "does not take an argument"},
+		{"%>m", "only valid in %>s"},
+		{"%>", "unknown log directive"},
+	}
+	for _, tt := range tests {
+		_, err := CompileLogFormat(tt.format)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("CompileLogFormat(%q): got %v, want %q", tt.format, err, tt.wantErr)
+		}
+	}
+	if catchPanic(func() { MustCompileLogFormat("%z") }) == nil {
+		t.Error("MustCompileLogFormat did not panic on an invalid format")
+	}
+}
+
+func TestLoggerWithCompiledFormat(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{Output: &buf, Formatter: MustCompileLogFormat(`%m %U%q %>s %B %{X-Request-Id}i %{Content-Type}o`)}))
+	router.GET("/x", func(c *Context) { c.String(http.StatusOK, "hello") })
+	req := httptest.NewRequest("GET", "/x?a=1", nil)
+	req.Header.Set("X-Request-Id", "rid")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if want := "GET /x?a=1 200 5 rid text/plain; charset=utf-8\n"; buf.String() != want {
+		t.Errorf("logged %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,170 @@
+package gin_web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// JSONLogFields 是JSON访问日志的字段名。 JSONLogFields are the field names of the JSON access log.
+// 空名称使用默认值，"-"省略该字段。 An empty name uses the default, "-" omits the field.
+type JSONLogFields struct {
+	Time            string // 默认 default "time"
+	Status          string // 默认 default "status"
+	Latency         string // 默认 default "latency_ms"，毫秒 in milliseconds
+	ClientIP        string // 默认 default "client_ip"
+	Method          string // 默认 default "method"
+	Path            string // 默认 default "path"
+	Error           string // 默认 default "error"，为空时省略 omitted when empty
+	BodySize        string // 默认 default "body_size"
+	Keys            string // 默认 default "keys"，只包含JSONLogConfig.Keys，为空时省略 only JSONLogConfig.Keys, omitted when empty
+	RequestID       string // 默认 default "request_id"，为空时省略 omitted when empty
+	RequestHeaders  string // 默认 default "request_headers"
+	ResponseHeaders string // 默认 default "response_headers"
+}
+
+// JSONLogConfig 配置JSONLogFormatter JSONLogConfig configures JSONLogFormatter.
+type JSONLogConfig struct {
+	Fields JSONLogFields
+	//要记录的请求标头 RequestHeaders are the request headers to log.
+	RequestHeaders []string
+	//要记录的响应标头 ResponseHeaders are the response headers to log.
+	ResponseHeaders []string
+	//要记录的c.Keys条目，默认不记录，以免上下文中的机密泄露到日志中 Keys are the c.Keys entries to log, none by default
+	//so that secrets stored in the context don't leak into the logs.
+	Keys []string
+	//时间格式，默认为time.RFC3339Nano TimeFormat is the time layout, time.RFC3339Nano by default.
+	TimeFormat string
+}
+
+// JSONLogFormatter 返回一个LogFormatter，每个请求写入一行JSON对象，字段顺序固定。 JSONLogFormatter returns a LogFormatter writing one JSON object
+// per request on a single line, in a fixed field order.
+// 输出从不包含ANSI代码：控制字符总是被转义。 The output never holds ANSI codes: control characters are always escaped.
+//
+//	router.Use(LoggerWithConfig(LoggerConfig{Formatter: JSONLogFormatter(JSONLogConfig{RequestHeaders: []string{"User-Agent"}})}))
+func JSONLogFormatter(config JSONLogConfig) LogFormatter {
+	fields := config.Fields
+	setDefaultName(&fields.Time, "time")
+	setDefaultName(&fields.Status, "status")
+	setDefaultName(&fields.Latency, "latency_ms")
+	setDefaultName(&fields.ClientIP, "client_ip")
+	setDefaultName(&fields.Method, "method")
+	setDefaultName(&fields.Path, "path")
+	setDefaultName(&fields.Error, "error")
+	setDefaultName(&fields.BodySize, "body_size")
+	setDefaultName(&fields.Keys, "keys")
+	setDefaultName(&fields.RequestID, "request_id")
+	setDefaultName(&fields.RequestHeaders, "request_headers")
+	setDefaultName(&fields.ResponseHeaders, "response_headers")
+	timeFormat := config.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339Nano
+	}
+
+	return func(param LogFormatterParam) string {
+		var obj jsonObject
+		obj.field(fields.Time, param.TimeStamp.Format(timeFormat))
+		obj.field(fields.Status, param.StatusCode)
+		obj.field(fields.Latency, float64(param.Latency)/float64(time.Millisecond))
+		obj.field(fields.ClientIP, param.ClientIP)
+		obj.field(fields.Method, param.Method)
+		obj.field(fields.Path, param.Path)
+		obj.field(fields.BodySize, param.BodySize)
+		if param.RequestID != "" {
+			obj.field(fields.RequestID, param.RequestID)
+		}
+		if param.ErrorMessge != "" {
+			obj.field(fields.Error, param.ErrorMessge)
+		}
+		if keys, ok := logKeys(param.Keys, config.Keys); ok {
+			obj.field(fields.Keys, keys)
+		}
+		if len(config.RequestHeaders) > 0 && param.Request != nil {
+			obj.field(fields.RequestHeaders, selectHeaders(param.Request.Header, config.RequestHeaders))
+		}
+		if len(config.ResponseHeaders) > 0 {
+			obj.field(fields.ResponseHeaders, selectHeaders(param.ResponseHeader, config.ResponseHeaders))
+		}
+		return obj.String()
+	}
+}
+
+func setDefaultName(name *string, value string) {
+	if *name == "" {
+		*name = value
+	}
+}
+
+// jsonObject 按添加顺序构建单行JSON对象 jsonObject builds a single line JSON object in the order the fields are added.
+type jsonObject struct {
+	buf bytes.Buffer
+}
+
+func (o *jsonObject) field(name string, value interface{}) {
+	if name == "-" {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	if o.buf.Len() == 0 {
+		o.buf.WriteByte('{')
+	} else {
+		o.buf.WriteByte(',')
+	}
+	key, _ := json.Marshal(name)
+	o.buf.Write(key)
+	o.buf.WriteByte(':')
+	o.buf.Write(data)
+}
+
+func (o *jsonObject) String() string {
+	if o.buf.Len() == 0 {
+		return "{}\n"
+	}
+	return o.buf.String() + "}\n"
+}
+
+// logKeys 按allowed的顺序返回其中存在的键，其他值使用fmt格式化；没有键时ok为false。 logKeys returns the keys of allowed which are present,
+// in the order of allowed, values which can't be encoded as JSON are formatted with fmt; ok is false if there is none.
+func logKeys(keys map[string]interface{}, allowed []string) (raw json.RawMessage, ok bool) {
+	var obj jsonObject
+	for _, name := range allowed {
+		if value, exists := keys[name]; exists {
+			obj.field(name, value)
+		}
+	}
+	if obj.buf.Len() == 0 {
+		return nil, false
+	}
+	s := obj.String()
+	return json.RawMessage(s[:len(s)-1]), true
+}
+
+// selectHeaders 返回给定标头的值，多个值用", "连接 selectHeaders returns the values of the given headers, several values are joined with ", ".
+func selectHeaders(header http.Header, names []string) map[string]string {
+	selected := make(map[string]string, len(names))
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			selected[http.CanonicalHeaderKey(name)] = joinHeaderValues(values)
+		}
+	}
+	return selected
+}
+
+func joinHeaderValues(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	var buf bytes.Buffer
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(v)
+	}
+	return buf.String()
+}
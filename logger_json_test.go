@@ -0,0 +1,97 @@
+package gin_web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLogFormatterFields(t *testing.T) {
+	req := httptest.NewRequest("GET", "/items?x=1", nil)
+	req.Header.Set("User-Agent", "test")
+	req.Header.Add("Accept", "a")
+	req.Header.Add("Accept", "b")
+	param := LogFormatterParam{
+		Request:        req,
+		TimeStamp:      time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		StatusCode:     http.StatusOK,
+		Latency:        1500 * time.Microsecond,
+		ClientIP:       "1.2.3.4",
+		Method:         "GET",
+		Path:           "/items?x=1",
+		BodySize:       42,
+		Keys:           map[string]interface{}{"user": "gin", "token": "secret", "n": complex(1, 2)},
+		ResponseHeader: http.Header{"Content-Type": {"text/plain"}},
+	}
+
+	tests := []struct {
+		name   string
+		config JSONLogConfig
+		param  func(p LogFormatterParam) LogFormatterParam
+		want   string
+	}{
+		{"defaults omit keys", JSONLogConfig{}, nil,
+			`{"time":"2020-01-02T03:04:05Z","status":200,"latency_ms":1.5,"client_ip":"1.2.3.4","method":"GET","path":"/items?x=1","body_size":42}`},
+		{"allowed keys in order", JSONLogConfig{Keys: []string{"user", "missing", "n"}}, nil,
+			`{"time":"2020-01-02T03:04:05Z","status":200,"latency_ms":1.5,"client_ip":"1.2.3.4","method":"GET","path":"/items?x=1","body_size":42,"keys":{"user":"gin","n":"(1+2i)"}}`},
+		{"no allowed key present", JSONLogConfig{Keys: []string{"missing"}}, nil,
+			`{"time":"2020-01-02T03:04:05Z","status":200,"latency_ms":1.5,"client_ip":"1.2.3.4","method":"GET","path":"/items?x=1","body_size":42}`},
+		{"renamed and omitted fields", JSONLogConfig{Fields: JSONLogFields{Time: "-", Latency: "-", Status: "code", ClientIP: "-", Path: "-", BodySize: "-"}}, nil,
+			`{"code":200,"method":"GET"}`},
+		{"error and request id", JSONLogConfig{}, func(p LogFormatterParam) LogFormatterParam {
+			p.ErrorMessge, p.RequestID = "boom", "abc"
+			return p
+		}, `{"time":"2020-01-02T03:04:05Z","status":200,"latency_ms":1.5,"client_ip":"1.2.3.4","method":"GET","path":"/items?x=1","body_size":42,"request_id":"abc","error":"boom"}`},
+		{"headers", JSONLogConfig{RequestHeaders: []string{"user-agent", "Accept", "X-Missing"}, ResponseHeaders: []string{"Content-Type"}, TimeFormat: time.Kitchen}, nil,
+			`{"time":"3:04AM","status":200,"latency_ms":1.5,"client_ip":"1.2.3.4","method":"GET","path":"/items?x=1","body_size":42,"request_headers":{"Accept":"a, b","User-Agent":"test"},"response_headers":{"Content-Type":"text/plain"}}`},
+	}
+	for _, tt := range tests {
+		p := param
+		if tt.param != nil {
+			p = tt.param(p)
+		}
+		if got := JSONLogFormatter(tt.config)(p); got != tt.want+"\n" {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJSONLogFormatterEscaping(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"ansi", "\x1b[31mred\x1b[0m\x1b]0;title\x07", "\x1b[31mred\x1b[0m\x1b]0;title\x07"},
+		{"newlines", "a\nb\r\nc", "a\nb\r\nc"},
+		{"quotes and backslashes", `say "hi"\n`, `say "hi"\n`},
+		{"html and unicode separators", "<script>&\u2028\u2029", "<script>&\u2028\u2029"},
+		{"unicode", "\u00e9\u4e2d", "\u00e9\u4e2d"},
+		{"invalid utf8", "\xff\xfe", "\ufffd\ufffd"},
+	}
+	format := JSONLogFormatter(JSONLogConfig{Keys: []string{"k"}})
+	for _, tt := range tests {
+		line := format(LogFormatterParam{Path: tt.in, ErrorMessge: tt.in, Keys: map[string]interface{}{"k": tt.in}})
+		if !strings.HasSuffix(line, "}\n") || strings.Count(line, "\n") != 1 {
+			t.Errorf("%s: not a single line: %q", tt.name, line)
+		}
+		if strings.ContainsAny(line[:len(line)-1], "\x1b\x07\r\n\u2028\u2029<>&") {
+			t.Errorf("%s: unescaped control or HTML characters in %q", tt.name, line)
+		}
+		var entry struct {
+			Path  string            `json:"path"`
+			Error string            `json:"error"`
+			Keys  map[string]string `json:"keys"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Errorf("%s: invalid JSON %q: %v", tt.name, line, err)
+			continue
+		}
+		if entry.Path != tt.want || entry.Error != tt.want || entry.Keys["k"] != tt.want {
+			t.Errorf("%s: decoded %q, %q, %q; want %q", tt.name, entry.Path, entry.Error, entry.Keys["k"], tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gin_web
+
+import (
+	"math/rand"
+	"path"
+	"strings"
+)
+
+// skipPatterns 是LoggerConfig.SkipPathPatterns的编译形式 skipPatterns is the compiled form of LoggerConfig.SkipPathPatterns.
+type skipPatterns struct {
+	globs    []string
+	prefixes []string
+}
+
+// compileSkipPatterns 验证模式，无效的模式会惊慌 compileSkipPatterns validates the patterns, an invalid pattern panics.
+func compileSkipPatterns(patterns []string) skipPatterns {
+	var compiled skipPatterns
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic("无效的跳过路径模式 invalid skip path pattern '" + pattern + "': " + err.Error())
+		}
+		if prefix := strings.TrimSuffix(pattern, "/**"); prefix != pattern {
+			compiled.prefixes = append(compiled.prefixes, prefix)
+		} else {
+			compiled.globs = append(compiled.globs, pattern)
+		}
+	}
+	return compiled
+}
+
+// match 判断路径是否匹配任一模式。前缀模式与路径的相同数量的段匹配， match reports whether the path matches any pattern.
+// 因此 /api/*/internal/** 匹配 /api/v1/internal 及其下的所有路径。 A prefix pattern is matched against as many segments of the path,
+// so /api/*/internal/** matches /api/v1/internal and every path under it.
+func (p skipPatterns) match(urlPath string) bool {
+	for _, prefix := range p.prefixes {
+		candidate := urlPath
+		if i := nthIndexByte(urlPath, '/', strings.Count(prefix, "/")+1); i >= 0 {
+			candidate = urlPath[:i]
+		}
+		if ok, _ := path.Match(prefix, candidate); ok {
+			return true
+		}
+	}
+	for _, glob := range p.globs {
+		if ok, _ := path.Match(glob, urlPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// nthIndexByte 返回s中第n个c的索引，如果没有则返回-1 nthIndexByte returns the index of the n'th c in s, -1 if there is none.
+func nthIndexByte(s string, c byte, n int) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			if n--; n == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// sampled 根据状态码的采样率判断是否应记录请求 sampled reports whether a request should be logged according to the sample rate of its status.
+func sampled(rates map[int]float64, status int) bool {
+	if len(rates) == 0 {
+		return true
+	}
+	rate, ok := rates[status]
+	if !ok {
+		if rate, ok = rates[status/100]; !ok {
+			return true
+		}
+	}
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	}
+	return rand.Float64() < rate
+}
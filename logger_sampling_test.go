@@ -0,0 +1,122 @@
+package gin_web
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSkipPatterns(t *testing.T) {
+	patterns := compileSkipPatterns([]string{"/health*", "/api/*/internal/**", "/static/*.css"})
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/healthz", true},
+		{"/health", true},
+		{"/health/live", false},
+		{"/api/v1/internal", true},
+		{"/api/v1/internal/a/b", true},
+		{"/api/v1/internals", false},
+		{"/api/v1/public", false},
+		{"/static/site.css", true},
+		{"/static/css/site.css", false},
+		{"/", false},
+	}
+	for _, tt := range tests {
+		if got := patterns.match(tt.path); got != tt.want {
+			t.Errorf("match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if catchPanic(func() { compileSkipPatterns([]string{"/[a"}) }) == nil {
+		t.Error("compileSkipPatterns accepted an invalid pattern")
+	}
+}
+
+func TestSampled(t *testing.T) {
+	tests := []struct {
+		rates  map[int]float64
+		status int
+		want   bool
+	}{
+		{nil, http.StatusOK, true},
+		{map[int]float64{2: 0}, http.StatusOK, false},
+		{map[int]float64{2: 0}, http.StatusNotFound, true},
+		{map[int]float64{2: 0, 201: 1}, http.StatusCreated, true},
+		{map[int]float64{4: 1, 404: 0}, http.StatusNotFound, false},
+		{map[int]float64{5: 2}, http.StatusInternalServerError, true},
+		{map[int]float64{5: -1}, http.StatusInternalServerError, false},
+	}
+	for _, tt := range tests {
+		if got := sampled(tt.rates, tt.status); got != tt.want {
+			t.Errorf("sampled(%v, %d) = %v, want %v", tt.rates, tt.status, got, tt.want)
+		}
+	}
+	logged := 0
+	for i := 0; i < 10000; i++ {
+		if sampled(map[int]float64{2: 0.5}, http.StatusOK) {
+			logged++
+		}
+	}
+	if logged < 4000 || logged > 6000 {
+		t.Errorf("sampled %d of 10000 requests at 0.5", logged)
+	}
+}
+
+func TestLoggerSkipAndSampling(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{
+		Output:           &buf,
+		Formatter:        MustCompileLogFormat("%U %s"),
+		SkipPaths:        []string{"/skipped"},
+		SkipPathPatterns: []string{"/health*", "/api/*/internal/**"},
+		Skip:             func(c *Context) bool { return c.Query("quiet") == "1" },
+		SampleRates:      map[int]float64{2: 0, 201: 1},
+		SlowThreshold:    30 * time.Millisecond,
+	}))
+	router.GET("/*p", func(c *Context) {
+		switch c.Param("p") {
+		case "/slow":
+			time.Sleep(40 * time.Millisecond)
+		case "/err":
+			c.String(http.StatusInternalServerError, "x")
+		case "/created":
+			c.String(http.StatusCreated, "x")
+		case "/failed", "/healthz", "/skipped":
+			if c.Query("fail") == "1" {
+				c.Error(errors.New("failed"))
+			}
+		}
+	})
+
+	tests := []struct {
+		url    string
+		logged bool
+	}{
+		{"/healthz", false},
+		{"/api/v1/internal/a", false},
+		{"/api/v1/public", false},
+		{"/err?quiet=1", false},
+		{"/slow", true},
+		{"/err", true},
+		{"/created", true},
+		{"/failed?fail=1", true},
+		{"/failed?fail=1&quiet=1", true},
+		{"/healthz?fail=1", true},
+		{"/skipped?fail=1", true},
+		{"/skipped", false},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.url, nil))
+		path := strings.SplitN(tt.url, "?", 2)[0]
+		if logged := strings.HasPrefix(buf.String(), path+" "); logged != tt.logged {
+			t.Errorf("%s: logged = %v, want %v (%q)", tt.url, logged, tt.logged, buf.String())
+		}
+	}
+}
@@ -3,14 +3,52 @@ package gin_web
 import (
 	"io"
 	"os"
+	"sync/atomic"
 )
 
 var DefaultWriter io.Writer = os.Stdout
 
-const(
+const (
+	// DebugMode 表示gin-web处于调试模式 DebugMode indicates gin-web mode is debug.
+	DebugMode = "debug"
+	// ReleaseMode 表示gin-web处于发布模式 ReleaseMode indicates gin-web mode is release.
+	ReleaseMode = "release"
+	// TestMode 表示gin-web处于测试模式 TestMode indicates gin-web mode is test.
+	TestMode = "test"
+)
+
+const (
 	debugCode = iota
 	releaseCode
 	testCode
 )
 
-var ginMode = debugCode
\ No newline at end of file
+// ginMode 保存当前模式代码，原子地读写，以便请求可以与SetMode并发读取它 ginMode holds the current mode code, it is read and written
+// atomically so requests may read it concurrently with SetMode.
+var ginMode int32 = debugCode
+
+// SetMode 根据输入字符串设置gin-web模式，空字符串表示调试模式。 SetMode sets gin-web mode according to input string, an empty string means debug mode.
+func SetMode(value string) {
+	switch value {
+	case DebugMode, "":
+		atomic.StoreInt32(&ginMode, debugCode)
+	case ReleaseMode:
+		atomic.StoreInt32(&ginMode, releaseCode)
+	case TestMode:
+		atomic.StoreInt32(&ginMode, testCode)
+	default:
+		panic("gin-web mode unknown: " + value + " (available mode: debug release test)")
+	}
+}
+
+// Mode 返回当前的gin-web模式 Mode returns currently gin-web mode.
+func Mode() string {
+	switch atomic.LoadInt32(&ginMode) {
+	case releaseCode:
+		return ReleaseMode
+	case testCode:
+		return TestMode
+	default:
+		return DebugMode
+	}
+}
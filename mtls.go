@@ -0,0 +1,326 @@
+package gin_web
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientAuthConfig 配置双向TLS客户端认证 ClientAuthConfig configures mutual TLS client authentication.
+type ClientAuthConfig struct {
+	//用于验证客户端证书的PEM格式的CA证书包 CAFile is the PEM bundle of the CAs verifying the client certificates.
+	CAFile string
+	//验证模式，默认为tls.RequireAndVerifyClientCert ClientAuth is the verify mode, tls.RequireAndVerifyClientCert by default.
+	ClientAuth tls.ClientAuthType
+	//可选的PEM或DER格式的证书吊销列表，被吊销的客户端证书会被拒绝 CRLFile is an optional PEM or DER revocation list, the revoked client certificates are rejected.
+	//文件在磁盘上变化时会像CertReloader一样重新加载；CRL超过NextUpdate后所有客户端证书都会被拒绝。
+	//The file is reloaded when it changes on disk, like CertReloader does. Every client certificate is rejected once the CRL is past its NextUpdate.
+	CRLFile string
+}
+
+// Apply 在tlsConfig上设置客户端CA池、验证模式和吊销检查 Apply sets the client CA pool, the verify mode and the revocation check on tlsConfig.
+func (config ClientAuthConfig) Apply(tlsConfig *tls.Config) error {
+	caPEM, err := os.ReadFile(config.CAFile)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("%s中没有CA证书 no CA certificate in %s", config.CAFile, config.CAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = config.ClientAuth
+	if tlsConfig.ClientAuth == tls.NoClientCert {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if config.CRLFile == "" {
+		return nil
+	}
+
+	crl := &crlReloader{file: config.CRLFile, caPEM: caPEM}
+	if err := crl.load(); err != nil {
+		return err
+	}
+	if err := crl.current().checkExpiry(); err != nil {
+		return err
+	}
+	//VerifyConnection也会在恢复的会话上运行，而VerifyPeerCertificate不会 // VerifyConnection also runs on resumed sessions, VerifyPeerCertificate doesn't
+	verifyConnection := tlsConfig.VerifyConnection
+	tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		if err := crl.verify(cs.VerifiedChains); err != nil {
+			return err
+		}
+		if verifyConnection != nil {
+			return verifyConnection(cs)
+		}
+		return nil
+	}
+	return nil
+}
+
+// revocationList 是已加载的CRL及其吊销的序列号 revocationList is a loaded CRL and its revoked serial numbers.
+type revocationList struct {
+	*x509.RevocationList
+	revoked map[string]struct{}
+	modTime time.Time
+}
+
+// checkExpiry 在CRL超过NextUpdate后返回错误，因为之后的吊销不再可知。 checkExpiry returns an error once the CRL is past its NextUpdate,
+// since the revocations issued since then are unknown.
+func (list *revocationList) checkExpiry() error {
+	if !list.NextUpdate.IsZero() && time.Now().After(list.NextUpdate) {
+		return fmt.Errorf("CRL已于%s过期 the CRL expired at %s", list.NextUpdate.Format(time.RFC3339), list.NextUpdate.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// crlReloader 在CRL文件发生变化时重新加载它，最多每CertReloadInterval检查一次。 crlReloader reloads the CRL file when it changes on disk,
+// checking at most every CertReloadInterval. 重新加载失败时保留之前的CRL。 The previous CRL is kept when a reload fails.
+type crlReloader struct {
+	file    string
+	caPEM   []byte
+	mu      sync.RWMutex
+	list    *revocationList
+	checked time.Time
+}
+
+// verify 在必要时重新加载CRL，并拒绝过期的CRL和被吊销的客户端证书 verify reloads the CRL if needed and rejects an expired CRL
+// and the revoked client certificates.
+func (r *crlReloader) verify(verifiedChains [][]*x509.Certificate) error {
+	r.reload()
+	list := r.current()
+	if err := list.checkExpiry(); err != nil {
+		return err
+	}
+	for _, chain := range verifiedChains {
+		leaf := chain[0]
+		if !bytes.Equal(leaf.RawIssuer, list.RawIssuer) {
+			continue
+		}
+		if _, ok := list.revoked[leaf.SerialNumber.String()]; ok {
+			return fmt.Errorf("客户端证书已被吊销 client certificate %s has been revoked", leaf.SerialNumber)
+		}
+	}
+	return nil
+}
+
+func (r *crlReloader) current() *revocationList {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.list
+}
+
+func (r *crlReloader) load() error {
+	info, err := os.Stat(r.file)
+	if err != nil {
+		return err
+	}
+	crl, err := loadRevocationList(r.file, r.caPEM)
+	if err != nil {
+		return err
+	}
+	list := &revocationList{RevocationList: crl, revoked: make(map[string]struct{}, len(crl.RevokedCertificateEntries)), modTime: info.ModTime()}
+	for _, entry := range crl.RevokedCertificateEntries {
+		list.revoked[entry.SerialNumber.String()] = struct{}{}
+	}
+	r.mu.Lock()
+	r.list, r.checked = list, time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// reload 如果距上次检查已过CertReloadInterval且文件已修改，则重新加载CRL reload reloads the CRL if CertReloadInterval elapsed
+// since the last check and the file was modified.
+func (r *crlReloader) reload() {
+	r.mu.Lock()
+	if time.Since(r.checked) < CertReloadInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.checked = time.Now()
+	modTime := r.list.modTime
+	r.mu.Unlock()
+
+	if info, err := os.Stat(r.file); err != nil || info.ModTime().Equal(modTime) {
+		return
+	}
+	if err := r.load(); err != nil {
+		//文件可能只写了一半，保留旧CRL并在下次重试 // the file may be half written, keep the old CRL and retry next time
+		DebugPrint("[警告]无法重新加载CRL [WARNING] Could not reload CRL %s: %v", r.file, err)
+		return
+	}
+	DebugPrint("已重新加载CRL Reloaded CRL %s", r.file)
+}
+
+// loadRevocationList 加载CRL，并用CA包中的签发者验证其签名 loadRevocationList loads the CRL and checks its signature with its issuer from the CA bundle.
+func loadRevocationList(crlFile string, caPEM []byte) (*x509.RevocationList, error) {
+	data, err := os.ReadFile(crlFile)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, err
+	}
+	for len(caPEM) > 0 {
+		var block *pem.Block
+		if block, caPEM = pem.Decode(caPEM); block == nil {
+			break
+		}
+		ca, err := x509.ParseCertificate(block.Bytes)
+		if err != nil || !bytes.Equal(ca.RawSubject, crl.RawIssuer) {
+			continue
+		}
+		if err := crl.CheckSignatureFrom(ca); err != nil {
+			return nil, fmt.Errorf("无效的CRL签名 invalid CRL signature in %s: %v", crlFile, err)
+		}
+		return crl, nil
+	}
+	return nil, fmt.Errorf("CA包中没有%s的签发者 the issuer of %s is not in the CA bundle", crlFile, crlFile)
+}
+
+// RunMutualTLS 与RunTLS类似，但是按照clientAuth要求并验证客户端证书。 RunMutualTLS is like RunTLS but requires and verifies the client certificates
+// according to clientAuth. 使用Context.ClientIdentity获取调用者。 Use Context.ClientIdentity to get the caller.
+func (engine *Engine) RunMutualTLS(addr, certFile, keyFile string, clientAuth ClientAuthConfig) (err error) {
+	defer func() { debugPrintError(err) }()
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if err = clientAuth.Apply(config); err != nil {
+		return
+	}
+	DebugPrint("监听并提供双向TLS服务 Listening and serving mutual TLS on %s\n", addr)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return
+	}
+	err = engine.serve(context.Background(), listener, serveTLS, ServerConfig{
+		Configure: func(srv *http.Server) { srv.TLSConfig = config },
+	})
+	return
+}
+
+// ClientIdentity 是已验证的客户端证书的身份 ClientIdentity is the identity of a verified client certificate.
+type ClientIdentity struct {
+	//证书主题的字符串形式，例如 "CN=api,O=example" Subject is the string form of the certificate subject, e.g. "CN=api,O=example".
+	Subject        string
+	CommonName     string
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+	//第一个spiffe:// URI SAN，例如 "spiffe://example.org/ns/prod/sa/api" SPIFFEID is the first spiffe:// URI SAN, e.g. "spiffe://example.org/ns/prod/sa/api".
+	SPIFFEID string
+	//已验证的客户端证书 Certificate is the verified client certificate.
+	Certificate *x509.Certificate
+}
+
+// ClientCertificate 返回已验证的客户端证书，如果没有则返回nil。 ClientCertificate returns the verified client certificate, nil if there is none.
+// 未验证的证书（例如tls.RequireAnyClientCert）永远不会返回。 Unverified certificates, e.g. with tls.RequireAnyClientCert, are never returned.
+func (c *Context) ClientCertificate() *x509.Certificate {
+	c.checkReleased()
+	if c.Request == nil || c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+	return c.Request.TLS.VerifiedChains[0][0]
+}
+
+// ClientIdentity 返回已验证的客户端证书的身份，如果没有则返回nil。 ClientIdentity returns the identity of the verified client certificate, nil if there is none.
+func (c *Context) ClientIdentity() *ClientIdentity {
+	cert := c.ClientCertificate()
+	if cert == nil {
+		return nil
+	}
+	identity := &ClientIdentity{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		IPAddresses:    cert.IPAddresses,
+		URIs:           cert.URIs,
+		Certificate:    cert,
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			identity.SPIFFEID = uri.String()
+			break
+		}
+	}
+	return identity
+}
+
+// IDs 返回可以与允许的身份匹配的所有名称：SPIFFE ID、URI、DNS SAN、电子邮件和通用名称。 IDs returns every name which can be matched
+// against the allowed identities: the SPIFFE ID, URIs, DNS SANs, emails and common name.
+func (identity *ClientIdentity) IDs() []string {
+	ids := make([]string, 0, len(identity.URIs)+len(identity.DNSNames)+len(identity.EmailAddresses)+1)
+	for _, uri := range identity.URIs {
+		ids = append(ids, uri.String())
+	}
+	ids = append(ids, identity.DNSNames...)
+	ids = append(ids, identity.EmailAddresses...)
+	if identity.CommonName != "" {
+		ids = append(ids, identity.CommonName)
+	}
+	return ids
+}
+
+// ClientIdentityKey 是RequireClientIdentity保存已允许身份的键 ClientIdentityKey is the key under which RequireClientIdentity stores the allowed identity.
+const ClientIdentityKey = "gin-web/client-identity"
+
+// ErrClientCertRequired 在请求没有已验证的客户端证书时由RequireClientIdentity记录。 ErrClientCertRequired is recorded by RequireClientIdentity
+// when the request has no verified client certificate.
+var ErrClientCertRequired = errors.New("需要客户端证书 a client certificate is required")
+
+// RequireClientIdentity 返回一个中间件，只允许身份与allowed之一匹配的调用者。 RequireClientIdentity returns a middleware allowing only the callers
+// whose identity matches one of allowed.
+// 以"*"结尾的条目匹配前缀，例如 "spiffe://example.org/ns/prod/*"。 An entry ending with "*" matches a prefix, e.g. "spiffe://example.org/ns/prod/*".
+// 没有已验证证书的请求以401中止，不允许的身份以403中止。 Requests without a verified certificate are aborted with 401, identities not allowed with 403.
+func RequireClientIdentity(allowed ...string) HandlerFunc {
+	return func(c *Context) {
+		identity := c.ClientIdentity()
+		if identity == nil {
+			c.AbortWithError(http.StatusUnauthorized, ErrClientCertRequired)
+			return
+		}
+		for _, id := range identity.IDs() {
+			if matchIdentity(allowed, id) {
+				c.Set(ClientIdentityKey, identity)
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithError(http.StatusForbidden, fmt.Errorf("不允许的客户端身份 client identity %q is not allowed", identity.Subject))
+	}
+}
+
+func matchIdentity(allowed []string, id string) bool {
+	for _, pattern := range allowed {
+		if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+			if strings.HasPrefix(id, prefix) {
+				return true
+			}
+		} else if pattern == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package gin_web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA 是签发客户端证书和CRL的测试CA testCA is a test CA issuing client certificates and CRLs.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) client(t *testing.T, serial int64) *x509.Certificate {
+	t.Helper()
+	return ca.clientPair(t, serial).Leaf
+}
+
+// clientPair 签发客户端证书并返回它及其私钥 clientPair issues a client certificate and returns it with its private key.
+func (ca *testCA) clientPair(t *testing.T, serial int64) tls.Certificate {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}
+}
+
+// writeCRL 写入吊销给定序列号的CRL writeCRL writes a CRL revoking the given serial numbers.
+func (ca *testCA) writeCRL(t *testing.T, file string, number int64, nextUpdate time.Time, serials ...int64) {
+	t.Helper()
+	list := &x509.RevocationList{Number: big.NewInt(number), ThisUpdate: nextUpdate.Add(-2 * time.Hour), NextUpdate: nextUpdate}
+	for _, serial := range serials {
+		list.RevokedCertificateEntries = append(list.RevokedCertificateEntries, x509.RevocationListEntry{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, list, ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientAuthCRL(t *testing.T) {
+	defer func(interval time.Duration) { CertReloadInterval = interval }(CertReloadInterval)
+	CertReloadInterval = 0
+
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile, crlFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "crl.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0600)
+	ca.writeCRL(t, crlFile, 1, time.Now().Add(time.Hour), 66)
+
+	config := &tls.Config{}
+	if err := (ClientAuthConfig{CAFile: caFile, CRLFile: crlFile}).Apply(config); err != nil {
+		t.Fatal(err)
+	}
+	if config.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("ClientAuth = %v, want RequireAndVerifyClientCert", config.ClientAuth)
+	}
+	verify := func(serial int64) error {
+		return config.VerifyConnection(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{ca.client(t, serial), ca.cert}}})
+	}
+	// touch 写入新的CRL并确保修改时间改变 touch writes a new CRL and makes sure its modification time changes
+	touch := func(number int64, nextUpdate time.Time, serials ...int64) {
+		ca.writeCRL(t, crlFile, number, nextUpdate, serials...)
+		future := time.Now().Add(time.Duration(number) * time.Second)
+		os.Chtimes(crlFile, future, future)
+	}
+
+	tests := []struct {
+		name    string
+		update  func()
+		serial  int64
+		wantErr string
+	}{
+		{"valid", func() {}, 5, ""},
+		{"revoked", func() {}, 66, "has been revoked"},
+		{"reloaded revocation", func() { touch(2, time.Now().Add(time.Hour), 66, 5) }, 5, "has been revoked"},
+		{"invalid file keeps the old CRL", func() {
+			os.WriteFile(crlFile, []byte("garbage"), 0600)
+			future := time.Now().Add(3 * time.Second)
+			os.Chtimes(crlFile, future, future)
+		}, 5, "has been revoked"},
+		{"reloaded unrevocation", func() { touch(4, time.Now().Add(time.Hour)) }, 66, ""},
+		{"expired", func() { touch(5, time.Now().Add(-time.Minute)) }, 5, "CRL expired"},
+	}
+	for _, tt := range tests {
+		tt.update()
+		err := verify(tt.serial)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: got %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if err := (ClientAuthConfig{CAFile: caFile, CRLFile: crlFile}).Apply(&tls.Config{}); err == nil || !strings.Contains(err.Error(), "CRL expired") {
+		t.Errorf("Apply with an expired CRL: got %v", err)
+	}
+	other := newTestCA(t)
+	other.writeCRL(t, crlFile, 6, time.Now().Add(time.Hour))
+	if err := (ClientAuthConfig{CAFile: caFile, CRLFile: crlFile}).Apply(&tls.Config{}); err == nil {
+		t.Error("Apply accepted a CRL from an unknown issuer")
+	}
+}
+
+func TestClientAuthCRLResumedSession(t *testing.T) {
+	defer func(interval time.Duration) { CertReloadInterval = interval }(CertReloadInterval)
+	CertReloadInterval = 0
+
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile, crlFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "crl.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0600)
+	ca.writeCRL(t, crlFile, 1, time.Now().Add(time.Hour))
+
+	serverCert, err := GenerateSelfSignedCert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := &tls.Config{Certificates: []tls.Certificate{serverCert}}
+	if err := (ClientAuthConfig{CAFile: caFile, CRLFile: crlFile}).Apply(serverConfig); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+	defer func() {
+		listener.Close()
+		<-done
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCert.Leaf)
+	clientConfig := &tls.Config{
+		RootCAs:            roots,
+		ServerName:         "127.0.0.1",
+		Certificates:       []tls.Certificate{ca.clientPair(t, 5)},
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+	//dial 读取服务器的响应，这也会处理会话票据 dial reads the response of the server, which also processes the session ticket
+	dial := func() (resumed bool, err error) {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+		if err != nil {
+			return false, err
+		}
+		defer conn.Close()
+		buf := make([]byte, 2)
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return false, err
+		}
+		return conn.ConnectionState().DidResume, nil
+	}
+
+	if _, err := dial(); err != nil {
+		t.Fatal(err)
+	}
+	if resumed, err := dial(); err != nil || !resumed {
+		t.Fatalf("second connection: resumed = %v, err = %v; want a resumed session", resumed, err)
+	}
+
+	ca.writeCRL(t, crlFile, 2, time.Now().Add(time.Hour), 5)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(crlFile, future, future)
+	if resumed, err := dial(); err == nil {
+		t.Errorf("a resumed session (resumed = %v) was accepted after the client certificate was revoked", resumed)
+	}
+}
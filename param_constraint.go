@@ -0,0 +1,151 @@
+package gin_web
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ParamConstraint 判断路径参数的值是否满足约束 ParamConstraint reports whether a path parameter value satisfies a constraint.
+// 它在路由查找期间被调用，因此必须是并发安全的 It is called during route lookup, so it must be safe for concurrent use.
+type ParamConstraint func(value string) bool
+
+// paramConstraints 保存按名称注册的约束，例如 :id<int>  paramConstraints holds the constraints registered by name, e.g. :id<int>
+var paramConstraints = map[string]ParamConstraint{
+	"int":   isIntParam,
+	"uint":  isUintParam,
+	"alpha": isAlphaParam,
+	"alnum": isAlnumParam,
+	"uuid":  isUUIDParam,
+}
+
+// RegisterParamConstraint 以给定名称注册一个约束，以便在路由中使用 :name<constraint>。
+// RegisterParamConstraint registers a constraint under the given name so that it can be used as :name<constraint> in routes.
+// 必须在注册使用它的路由之前调用，不是并发安全的。 It must be called before any route using it is registered and is not concurrency-safe.
+func RegisterParamConstraint(name string, fn ParamConstraint) {
+	if name == "" || fn == nil {
+		panic("约束名称和函数不能为空 constraint name and func can not be empty")
+	}
+	paramConstraints[name] = fn
+}
+
+// paramConstraint 是附加到参数节点的已解析约束 paramConstraint is a parsed constraint attached to a param node.
+type paramConstraint struct {
+	expr  string
+	match ParamConstraint
+}
+
+// parseParamWildcard 将 :name<expr> 拆分为参数名和约束。 parseParamWildcard splits :name<expr> into the param name and its constraint.
+// 如果通配符没有约束，则约束为nil。 The constraint is nil if the wildcard has none.
+// expr 先按已注册的名称查找，否则作为锚定的正则表达式编译。 expr is first looked up by registered name, otherwise compiled as an anchored regular expression.
+func parseParamWildcard(wildcard, fullPath string) (string, *paramConstraint) {
+	start := strings.IndexByte(wildcard, '<')
+	if start < 0 {
+		return wildcard[1:], nil
+	}
+	if wildcard[len(wildcard)-1] != '>' {
+		panic("参数约束必须以'>'结尾 param constraint must end with '>' in path '" + fullPath + "'")
+	}
+	name, expr := wildcard[1:start], wildcard[start+1:len(wildcard)-1]
+	if name == "" {
+		panic("通配符必须在路径中使用非空名称命名 wildcards must be named with a non-empty name in path '" + fullPath + "'")
+	}
+	if expr == "" {
+		panic("参数约束不能为空 param constraint can not be empty in path '" + fullPath + "'")
+	}
+	if fn, ok := paramConstraints[expr]; ok {
+		return name, &paramConstraint{expr: expr, match: fn}
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic("无效的参数约束 invalid param constraint '" + expr + "' in path '" + fullPath + "': " + err.Error())
+	}
+	return name, &paramConstraint{expr: expr, match: re.MatchString}
+}
+
+// paramKey 返回参数节点路径中的参数名 paramKey returns the param name of a param node path.
+func paramKey(path string) string {
+	if i := strings.IndexByte(path, '<'); i > 0 {
+		return path[1:i]
+	}
+	return path[1:]
+}
+
+// skipConstraint 返回 path[i] 处的 '<' 对应的 '>' 的索引 skipConstraint returns the index of the '>' closing the '<' at path[i].
+// 如果没有闭合，则返回 len(path)-1。 It returns len(path)-1 if the constraint is not closed.
+func skipConstraint(path string, i int) int {
+	depth := 0
+	for ; i < len(path); i++ {
+		switch path[i] {
+		case '<':
+			depth++
+		case '>':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(path) - 1
+}
+
+func isIntParam(s string) bool {
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return isUintParam(s)
+}
+
+func isUintParam(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphaParam(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnumParam(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c|0x20 < 'a' || c|0x20 > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUIDParam 匹配 8-4-4-4-12 格式的十六进制UUID isUUIDParam matches a hex UUID in the 8-4-4-4-12 form.
+func isUUIDParam(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if c := s[i]; (c < '0' || c > '9') && (c|0x20 < 'a' || c|0x20 > 'f') {
+				return false
+			}
+		}
+	}
+	return true
+}
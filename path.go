@@ -1,5 +1,7 @@
 package gin_web
 
+import "path"
+
 // Internal helper to lazily cereate a buffer if necessary
 // Calls to this function get inlined
 func bufApp(buf *[]byte, s string, w int, c byte) {
@@ -130,3 +132,23 @@ func cleanPath(p string) string {
 	}
 	return string(buf[:w])
 }
+
+func lastChar(str string) uint8 {
+	if str == "" {
+		panic("字符串长度不能为0 The length of the string can't be 0")
+	}
+	return str[len(str)-1]
+}
+
+// joinPaths 连接绝对路径和相对路径，并保留相对路径的尾部斜杠 joinPaths joins an absolute and a relative path, keeping the trailing slash of the relative one.
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+
+	finalPath := path.Join(absolutePath, relativePath)
+	if lastChar(relativePath) == '/' && lastChar(finalPath) != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}
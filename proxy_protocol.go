@@ -0,0 +1,256 @@
+package gin_web
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultProxyHeaderTimeout 是HeaderTimeout为0时读取PROXY头的超时 defaultProxyHeaderTimeout is the timeout reading the PROXY header when HeaderTimeout is 0.
+const defaultProxyHeaderTimeout = 5 * time.Second
+
+var (
+	// proxyV2Signature 是PROXY协议v2头的前12个字节 proxyV2Signature is the first 12 bytes of a PROXY protocol v2 header.
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	// ErrNoProxyHeader 在可信来源的连接没有以PROXY头开始时返回 ErrNoProxyHeader is returned when a connection from a trusted source does not start with a PROXY header.
+	ErrNoProxyHeader = errors.New("缺少PROXY协议头 missing PROXY protocol header")
+)
+
+// ProxyProtocolConfig 配置PROXY协议监听器 ProxyProtocolConfig configures the PROXY protocol listener.
+type ProxyProtocolConfig struct {
+	//允许发送PROXY头的负载均衡器的IP或CIDR，为空时不信任任何来源，信任所有来源需要显式设置"0.0.0.0/0"和"::/0"。
+	//TrustedSources are the IPs or CIDRs of the balancers allowed to send a PROXY header, no source is trusted when empty:
+	//trusting everyone requires an explicit "0.0.0.0/0" and "::/0".
+	//可信来源必须发送头，其他来源的连接保持不变。 Trusted sources must send the header, other connections are left untouched.
+	TrustedSources []string
+	//读取PROXY头的最长时间，默认为5秒 HeaderTimeout is the maximum duration for reading the PROXY header, 5 seconds by default.
+	HeaderTimeout time.Duration
+}
+
+// NewProxyProtocolListener 包装监听器，以便解析HAProxy PROXY协议v1和v2头， NewProxyProtocolListener wraps the listener so that HAProxy PROXY protocol
+// 并且连接的RemoteAddr反映真实的客户端。 v1 and v2 headers are parsed and the RemoteAddr of the connections reflects the real client.
+// 头在第一次Read或RemoteAddr时读取，因此Accept不会被慢速客户端阻塞。 The header is read on the first Read or RemoteAddr so that Accept is not blocked by slow clients.
+func NewProxyProtocolListener(listener net.Listener, config ProxyProtocolConfig) (net.Listener, error) {
+	trusted, err := parseTrustedSources(config.TrustedSources)
+	if err != nil {
+		return nil, err
+	}
+	if len(trusted) == 0 {
+		DebugPrint("[警告]没有可信来源，PROXY头不会被解析 [WARNING] No TrustedSources, PROXY headers will not be parsed")
+	}
+	timeout := config.HeaderTimeout
+	if timeout <= 0 {
+		timeout = defaultProxyHeaderTimeout
+	}
+	return &proxyListener{Listener: listener, trusted: trusted, timeout: timeout}, nil
+}
+
+// parseTrustedSources 将IP和CIDR解析为网络 parseTrustedSources parses IPs and CIDRs into networks.
+func parseTrustedSources(sources []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(sources))
+	for _, source := range sources {
+		if !strings.Contains(source, "/") {
+			ip := net.ParseIP(source)
+			if ip == nil {
+				return nil, fmt.Errorf("无效的IP invalid IP %q", source)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			source = fmt.Sprintf("%s/%d", source, bits)
+		}
+		_, network, err := net.ParseCIDR(source)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// containsIP 判断ip是否在任一网络中 containsIP reports whether ip is in any of the networks.
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type proxyListener struct {
+	net.Listener
+	trusted []*net.IPNet
+	timeout time.Duration
+}
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if ip := net.ParseIP(host); err != nil || ip == nil || !containsIP(l.trusted, ip) {
+		return conn, nil
+	}
+	return &proxyConn{Conn: conn, reader: bufio.NewReaderSize(conn, 256), timeout: l.timeout}, nil
+}
+
+// proxyConn 是来自可信来源的连接，其PROXY头被延迟读取 proxyConn is a connection from a trusted source whose PROXY header is read lazily.
+type proxyConn struct {
+	net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+	once    sync.Once
+	err     error
+	remote  net.Addr
+	local   net.Addr
+}
+
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		c.remote, c.local, c.err = readProxyHeader(c.reader)
+		c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	if c.readHeader(); c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	if c.readHeader(); c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyConn) LocalAddr() net.Addr {
+	if c.readHeader(); c.local != nil {
+		return c.local
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readProxyHeader 读取v1或v2 PROXY头，并返回源地址和目标地址。 readProxyHeader reads a v1 or v2 PROXY header and returns the source and destination addresses.
+// 对于UNKNOWN和LOCAL连接，地址为nil。 The addresses are nil for UNKNOWN and LOCAL connections.
+func readProxyHeader(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch first[0] {
+	case 'P':
+		return readProxyHeaderV1(r)
+	case '\r':
+		return readProxyHeaderV2(r)
+	}
+	return nil, nil, ErrNoProxyHeader
+}
+
+// readProxyHeaderV1 解析 "PROXY TCP4 src dst srcport dstport\r\n" readProxyHeaderV1 parses "PROXY TCP4 src dst srcport dstport\r\n".
+func readProxyHeaderV1(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	//v1头最多107个字节 // a v1 header is at most 107 bytes
+	line, err := r.ReadSlice('\n')
+	if err != nil || len(line) > 107 || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, errors.New("无效的PROXY v1头 invalid PROXY v1 header")
+	}
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) < 2 || fields[0] != "PROXY" {
+		return nil, nil, ErrNoProxyHeader
+	}
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, nil, fmt.Errorf("不支持的PROXY协议 unsupported PROXY protocol %q", fields[1])
+	}
+	if len(fields) != 6 {
+		return nil, nil, errors.New("无效的PROXY v1头 invalid PROXY v1 header")
+	}
+	src, err := parseProxyAddr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := parseProxyAddr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, dst, nil
+}
+
+func parseProxyAddr(ip, port string) (*net.TCPAddr, error) {
+	addr := &net.TCPAddr{IP: net.ParseIP(ip)}
+	if addr.IP == nil {
+		return nil, fmt.Errorf("PROXY头中的IP无效 invalid IP %q in PROXY header", ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("PROXY头中的端口无效 invalid port %q in PROXY header", port)
+	}
+	addr.Port = int(p)
+	return addr, nil
+}
+
+// readProxyHeaderV2 解析二进制v2头 readProxyHeaderV2 parses a binary v2 header.
+func readProxyHeaderV2(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(header[:12], proxyV2Signature) {
+		return nil, nil, ErrNoProxyHeader
+	}
+	if header[12]>>4 != 2 {
+		return nil, nil, fmt.Errorf("不支持的PROXY版本 unsupported PROXY version %d", header[12]>>4)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, nil, err
+	}
+
+	switch header[12] & 0x0f {
+	case 0x0: //LOCAL，例如健康检查 // LOCAL, e.g. health checks
+		return nil, nil, nil
+	case 0x1: //PROXY
+	default:
+		return nil, nil, fmt.Errorf("不支持的PROXY命令 unsupported PROXY command %d", header[12]&0x0f)
+	}
+	//只有TCP和UDP地址被使用，其他族保持原始地址 // only TCP and UDP addresses are used, other families keep the original addresses
+	var ipLen int
+	switch header[13] >> 4 {
+	case 0x1:
+		ipLen = net.IPv4len
+	case 0x2:
+		ipLen = net.IPv6len
+	default:
+		return nil, nil, nil
+	}
+	if len(body) < 2*ipLen+4 {
+		return nil, nil, errors.New("无效的PROXY v2地址 invalid PROXY v2 addresses")
+	}
+	src := &net.TCPAddr{
+		IP:   net.IP(append([]byte(nil), body[:ipLen]...)),
+		Port: int(binary.BigEndian.Uint16(body[2*ipLen:])),
+	}
+	dst := &net.TCPAddr{
+		IP:   net.IP(append([]byte(nil), body[ipLen:2*ipLen]...)),
+		Port: int(binary.BigEndian.Uint16(body[2*ipLen+2:])),
+	}
+	return src, dst, nil
+}
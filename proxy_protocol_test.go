@@ -0,0 +1,145 @@
+package gin_web
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// proxyV2Header 构建一个v2头 proxyV2Header builds a v2 header with the given command, family and address block.
+func proxyV2Header(command, family byte, body []byte) []byte {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x20|command, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(body)))
+	return append(header, body...)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	v4 := []byte{9, 8, 7, 6, 1, 1, 1, 1}
+	v4 = binary.BigEndian.AppendUint16(v4, 4242)
+	v4 = binary.BigEndian.AppendUint16(v4, 80)
+	v6 := append(net.ParseIP("2001:db8::1").To16(), net.ParseIP("::1").To16()...)
+	v6 = binary.BigEndian.AppendUint16(v6, 22)
+	v6 = binary.BigEndian.AppendUint16(v6, 443)
+
+	tests := []struct {
+		name    string
+		header  []byte
+		src     string
+		dst     string
+		wantErr string
+	}{
+		{"v1 tcp4", []byte("PROXY TCP4 1.2.3.4 5.6.7.8 1111 80\r\n"), "1.2.3.4:1111", "5.6.7.8:80", ""},
+		{"v1 tcp6", []byte("PROXY TCP6 2001:db8::1 ::1 22 443\r\n"), "[2001:db8::1]:22", "[::1]:443", ""},
+		{"v1 unknown", []byte("PROXY UNKNOWN\r\n"), "", "", ""},
+		{"v1 missing crlf", []byte("PROXY TCP4 1.2.3.4 5.6.7.8 1111 80\n"), "", "", "invalid PROXY v1 header"},
+		{"v1 too long", []byte("PROXY TCP4 " + strings.Repeat("1", 100) + "\r\n"), "", "", "invalid PROXY v1 header"},
+		{"v1 bad ip", []byte("PROXY TCP4 1.2.3 5.6.7.8 1111 80\r\n"), "", "", "invalid IP"},
+		{"v1 bad port", []byte("PROXY TCP4 1.2.3.4 5.6.7.8 70000 80\r\n"), "", "", "invalid port"},
+		{"v1 missing fields", []byte("PROXY TCP4 1.2.3.4\r\n"), "", "", "invalid PROXY v1 header"},
+		{"v1 udp", []byte("PROXY UDP4 1.2.3.4 5.6.7.8 1 2\r\n"), "", "", "unsupported PROXY protocol"},
+		{"v2 tcp4 with tlv", proxyV2Header(0x1, 0x11, append(v4, 1, 2, 3)), "9.8.7.6:4242", "1.1.1.1:80", ""},
+		{"v2 tcp6", proxyV2Header(0x1, 0x21, v6), "[2001:db8::1]:22", "[::1]:443", ""},
+		{"v2 local", proxyV2Header(0x0, 0x00, nil), "", "", ""},
+		{"v2 unix keeps addresses", proxyV2Header(0x1, 0x31, make([]byte, 216)), "", "", ""},
+		{"v2 short addresses", proxyV2Header(0x1, 0x11, v4[:6]), "", "", "invalid PROXY v2 addresses"},
+		{"v2 bad command", proxyV2Header(0x2, 0x11, v4), "", "", "unsupported PROXY command"},
+		{"v2 truncated", proxyV2Header(0x1, 0x11, v4)[:20], "", "", "EOF"},
+		{"no header", []byte("GET / HTTP/1.1\r\n"), "", "", "missing PROXY protocol header"},
+	}
+	for _, tt := range tests {
+		src, dst, err := readProxyHeader(bufio.NewReader(bytes.NewReader(tt.header)))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if got := addrString(src); got != tt.src {
+			t.Errorf("%s: source %q, want %q", tt.name, got, tt.src)
+		}
+		if got := addrString(dst); got != tt.dst {
+			t.Errorf("%s: destination %q, want %q", tt.name, got, tt.dst)
+		}
+	}
+}
+
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	header := []byte("PROXY TCP4 1.2.3.4 5.6.7.8 1111 80\r\n")
+	tests := []struct {
+		name    string
+		trusted []string
+		prefix  []byte
+		want    string
+	}{
+		{"trusted source", []string{"127.0.0.1"}, header, "1.2.3.4:1111"},
+		{"trusted without header", []string{"127.0.0.0/8"}, nil, "ERR"},
+		{"empty trusts nobody", nil, header, "ERR"},
+		{"empty leaves plain requests", nil, nil, "127.0.0.1:"},
+		{"untrusted source", []string{"10.0.0.0/8"}, header, "ERR"},
+		{"untrusted plain request", []string{"10.0.0.0/8"}, nil, "127.0.0.1:"},
+		{"everyone explicitly", []string{"0.0.0.0/0", "::/0"}, header, "1.2.3.4:1111"},
+	}
+	for _, tt := range tests {
+		router := New()
+		router.ProxyProtocol = &ProxyProtocolConfig{TrustedSources: tt.trusted, HeaderTimeout: time.Second}
+		router.GET("/", func(c *Context) { c.String(http.StatusOK, c.Request.RemoteAddr) })
+		listener := listen(t)
+		go router.RunListener(listener)
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetDeadline(time.Now().Add(2 * time.Second))
+		conn.Write(tt.prefix)
+		conn.Write([]byte("GET / HTTP/1.1\r\nHost: x\r\nConnection: close\r\n\r\n"))
+		got := "ERR"
+		if resp, err := http.ReadResponse(bufio.NewReader(conn), nil); err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode == http.StatusOK {
+				got = string(body)
+			}
+		}
+		conn.Close()
+		listener.Close()
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%s: got %q, want prefix %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseTrustedSources(t *testing.T) {
+	if _, err := parseTrustedSources([]string{"1.2.3"}); err == nil {
+		t.Error("expected an error for an invalid IP")
+	}
+	if _, err := parseTrustedSources([]string{"1.2.3.4/33"}); err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+	networks, err := parseTrustedSources([]string{"10.0.0.1", "2001:db8::/32"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ip, want := range map[string]bool{"10.0.0.1": true, "10.0.0.2": false, "2001:db8::5": true, "2001:db9::5": false} {
+		if got := containsIP(networks, net.ParseIP(ip)); got != want {
+			t.Errorf("containsIP(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
@@ -93,6 +93,12 @@ func timeFormat(t time.Time) string {
 
 // RecoveryWithWriter为给定的编写器返回一个中间件，该中间件可以从任何紧急情况中恢复，如果有中间件，则可以写入500。 RecoveryWithWriter returns a middleware for a given writer that recovers from any panics and writes a 500 if there was one.
 func RecoveryWithWriter(out io.Writer) HandlerFunc {
+	return recoveryWithWriter(out, false)
+}
+
+// recoveryWithWriter 在useSlog为true且引擎设置了slog日志记录器时，将恐慌写入slog而不是out。 recoveryWithWriter writes the panics
+// to slog instead of out when useSlog is true and the engine has a slog logger.
+func recoveryWithWriter(out io.Writer, useSlog bool) HandlerFunc {
 	var logger *log.Logger
 	if out != nil {
 		logger = log.New(out, "\n\n\x1b[31m", log.LstdFlags)
@@ -110,7 +116,9 @@ func RecoveryWithWriter(out io.Writer) HandlerFunc {
 						}
 					}
 				}
-				if logger != nil {
+				if slogger := c.engine.logger; useSlog && slogger != nil {
+					slogRecovery(slogger, c.Request, err, brokenPipe)
+				} else if logger != nil {
 					stack := stack(3)
 					httpRequest, _ := httputil.DumpRequest(c.Request, false)
 					headers := strings.Split(string(httpRequest), "\r\n")
@@ -143,6 +151,7 @@ func RecoveryWithWriter(out io.Writer) HandlerFunc {
 }
 
 // 恢复返回的中间件可从任何紧急情况中恢复，如果有中间件，则写入500。 Recovery returns a middleware that recovers from any panics and writes a 500 if there was one.
+// 如果引擎设置了slog日志记录器，恐慌以Error级别写入它。 If the engine has a slog logger, the panics are written to it at the Error level.
 func Recovery() HandlerFunc {
-	return RecoveryWithWriter(DefaultWriter)
+	return recoveryWithWriter(DefaultWriter, true)
 }
@@ -0,0 +1,201 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+)
+
+// JSON 包含给定的接口对象。 JSON contains the given interface object.
+type JSON struct {
+	Data interface{}
+}
+
+// IndentedJSON 包含给定的接口对象。 IndentedJSON contains the given interface object.
+type IndentedJSON struct {
+	Data interface{}
+}
+
+// SecureJSON 包含给定的接口对象及其前缀。 SecureJSON contains the given interface object and its prefix.
+type SecureJSON struct {
+	Prefix string
+	Data   interface{}
+}
+
+// JsonpJSON 包含给定的接口对象及其回调。 JsonpJSON contains the given interface object its callback.
+type JsonpJSON struct {
+	Callback string
+	Data     interface{}
+}
+
+// AsciiJSON 包含给定的接口对象。 AsciiJSON contains the given interface object.
+type AsciiJSON struct {
+	Data interface{}
+}
+
+// PureJSON 包含给定的接口对象，HTML字符不会被转义。 PureJSON contains the given interface object, HTML chars are not escaped.
+type PureJSON struct {
+	Data interface{}
+}
+
+var jsonContentType = []string{"application/json; charset=utf-8"}
+var jsonpContentType = []string{"application/javascript; charset=utf-8"}
+var jsonAsciiContentType = []string{"application/json"}
+
+// ErrInvalidCallback 在JSONP回调不是有效的JavaScript标识符路径时返回。 ErrInvalidCallback is returned when a JSONP callback is not a valid JavaScript identifier path.
+var ErrInvalidCallback = errors.New("无效的JSONP回调 invalid JSONP callback")
+
+// jsonpCallbackRegex 匹配诸如 cb 或 jQuery.handlers.cb_1 的回调 jsonpCallbackRegex matches callbacks such as cb or jQuery.handlers.cb_1
+var jsonpCallbackRegex = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(?:\.[a-zA-Z_$][a-zA-Z0-9_$]*)*$`)
+
+// ValidJSONPCallback 报告callback是否可以安全地用作JSONP回调。 ValidJSONPCallback reports whether callback is safe to use as a JSONP callback.
+func ValidJSONPCallback(callback string) bool {
+	return len(callback) <= 128 && jsonpCallbackRegex.MatchString(callback)
+}
+
+// Render (JSON) 写入数据并自定义ContentType。 Render (JSON) writes data with custom ContentType.
+func (r JSON) Render(w http.ResponseWriter) error {
+	return WriteJSON(w, r.Data)
+}
+
+// WriteContentType (JSON) 写入JSON ContentType。 WriteContentType (JSON) writes JSON ContentType.
+func (r JSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// WriteJSON 编组给定的接口对象，并使用自定义ContentType写入数据。 WriteJSON marshals the given interface object and writes it with custom ContentType.
+func WriteJSON(w http.ResponseWriter, obj interface{}) error {
+	writeContentType(w, jsonContentType)
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonBytes)
+	return err
+}
+
+// Render (IndentedJSON) 编组给定的接口对象，并使用自定义ContentType写入数据。 Render (IndentedJSON) marshals the given interface object and writes it with custom ContentType.
+func (r IndentedJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	jsonBytes, err := json.MarshalIndent(r.Data, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonBytes)
+	return err
+}
+
+// WriteContentType (IndentedJSON) 写入JSON ContentType。 WriteContentType (IndentedJSON) writes JSON ContentType.
+func (r IndentedJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// Render (SecureJSON) 编组给定的接口对象，并在JSON数组前加上前缀以防止JSON劫持。
+// Render (SecureJSON) marshals the given interface object and prepends the prefix to JSON arrays to prevent JSON hijacking.
+func (r SecureJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	jsonBytes, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	// 如果jsonBytes是数组值 if the jsonBytes is array values
+	if bytes.HasPrefix(jsonBytes, []byte("[")) && bytes.HasSuffix(jsonBytes, []byte("]")) {
+		if _, err = w.Write([]byte(r.Prefix)); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(jsonBytes)
+	return err
+}
+
+// WriteContentType (SecureJSON) 写入JSON ContentType。 WriteContentType (SecureJSON) writes JSON ContentType.
+func (r SecureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// Render (JsonpJSON) 编组给定的接口对象，并将其与回调一起写入。 Render (JsonpJSON) marshals the given interface object and writes it and its callback.
+// 没有回调时写入普通JSON，回调无效时返回ErrInvalidCallback。 Plain JSON is written without a callback, ErrInvalidCallback is returned for an invalid one.
+func (r JsonpJSON) Render(w http.ResponseWriter) (err error) {
+	if r.Callback != "" && !ValidJSONPCallback(r.Callback) {
+		return ErrInvalidCallback
+	}
+	r.WriteContentType(w)
+	ret, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+
+	if r.Callback == "" {
+		_, err = w.Write(ret)
+		return err
+	}
+
+	callback := template.JSEscapeString(r.Callback)
+	if _, err = w.Write([]byte("/**/ typeof " + callback + " === 'function' && " + callback + "(")); err != nil {
+		return err
+	}
+	if _, err = w.Write(ret); err != nil {
+		return err
+	}
+	_, err = w.Write([]byte(");"))
+	return err
+}
+
+// WriteContentType (JsonpJSON) 写入Javascript ContentType。 WriteContentType (JsonpJSON) writes Javascript ContentType.
+func (r JsonpJSON) WriteContentType(w http.ResponseWriter) {
+	if r.Callback == "" {
+		writeContentType(w, jsonContentType)
+		return
+	}
+	writeContentType(w, jsonpContentType)
+}
+
+// Render (AsciiJSON) 编组给定的接口对象，非ASCII字符被转义为\uXXXX。 Render (AsciiJSON) marshals the given interface object, non-ASCII chars are escaped as \uXXXX.
+func (r AsciiJSON) Render(w http.ResponseWriter) (err error) {
+	r.WriteContentType(w)
+	ret, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	for _, r := range string(ret) {
+		cvt := string(r)
+		if r >= 128 {
+			if r > 0xFFFF {
+				// 在UTF-16中编码为代理对 encode as an UTF-16 surrogate pair
+				r -= 0x10000
+				cvt = fmt.Sprintf("\\u%04x\\u%04x", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+			} else {
+				cvt = fmt.Sprintf("\\u%04x", int64(r))
+			}
+		}
+		buffer.WriteString(cvt)
+	}
+
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
+
+// WriteContentType (AsciiJSON) 写入JSON ContentType。 WriteContentType (AsciiJSON) writes JSON ContentType.
+func (r AsciiJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonAsciiContentType)
+}
+
+// Render (PureJSON) 编写自定义ContentType并使用给定的接口对象对JSON进行编码，不转义HTML字符。
+// Render (PureJSON) writes custom ContentType and encodes the given interface object without escaping HTML chars.
+func (r PureJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(r.Data)
+}
+
+// WriteContentType (PureJSON) 写入自定义ContentType。 WriteContentType (PureJSON) writes custom ContentType.
+func (r PureJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
@@ -0,0 +1,96 @@
+package render
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRenderJSON(t *testing.T) {
+	data := map[string]interface{}{"html": "<b>&</b>", "name": "gin"}
+	tests := []struct {
+		name        string
+		render      Render
+		want        string
+		contentType string
+	}{
+		{"json", JSON{data}, `{"html":"\u003cb\u003e\u0026\u003c/b\u003e","name":"gin"}`, "application/json; charset=utf-8"},
+		{"indented", IndentedJSON{map[string]int{"a": 1}}, "{\n    \"a\": 1\n}", "application/json; charset=utf-8"},
+		{"secure array", SecureJSON{"while(1);", []int{1, 2}}, "while(1);[1,2]", "application/json; charset=utf-8"},
+		{"secure object", SecureJSON{"while(1);", map[string]int{"a": 1}}, `{"a":1}`, "application/json; charset=utf-8"},
+		{"secure string", SecureJSON{"while(1);", "[x]"}, `"[x]"`, "application/json; charset=utf-8"},
+		{"jsonp", JsonpJSON{"cb", []int{1}}, "/**/ typeof cb === 'function' && cb([1]);", "application/javascript; charset=utf-8"},
+		{"jsonp dotted", JsonpJSON{"jQuery.handlers.cb_1", 1}, "/**/ typeof jQuery.handlers.cb_1 === 'function' && jQuery.handlers.cb_1(1);", "application/javascript; charset=utf-8"},
+		{"jsonp without callback", JsonpJSON{"", []int{1}}, "[1]", "application/json; charset=utf-8"},
+		{"ascii", AsciiJSON{map[string]string{"lang": "GO语言"}}, `{"lang":"GO\u8bed\u8a00"}`, "application/json"},
+		{"ascii surrogate pair", AsciiJSON{"a😀b"}, `"a\ud83d\ude00b"`, "application/json"},
+		{"ascii html", AsciiJSON{"<"}, `"\u003c"`, "application/json"},
+		{"pure", PureJSON{data}, "{\"html\":\"<b>&</b>\",\"name\":\"gin\"}\n", "application/json; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		if err := tt.render.Render(w); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := w.Body.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.name, ct, tt.contentType)
+		}
+
+		w = httptest.NewRecorder()
+		tt.render.WriteContentType(w)
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: WriteContentType wrote %q, want %q", tt.name, ct, tt.contentType)
+		}
+	}
+}
+
+func TestRenderJSONError(t *testing.T) {
+	for name, r := range map[string]Render{
+		"json":     JSON{make(chan int)},
+		"indented": IndentedJSON{make(chan int)},
+		"secure":   SecureJSON{"while(1);", make(chan int)},
+		"jsonp":    JsonpJSON{"cb", make(chan int)},
+		"ascii":    AsciiJSON{make(chan int)},
+		"pure":     PureJSON{make(chan int)},
+	} {
+		if err := r.Render(httptest.NewRecorder()); err == nil {
+			t.Errorf("%s: expected an error for an unsupported type", name)
+		}
+	}
+}
+
+func TestValidJSONPCallback(t *testing.T) {
+	tests := []struct {
+		callback string
+		valid    bool
+	}{
+		{"cb", true},
+		{"_cb$1", true},
+		{"jQuery.handlers.cb_1", true},
+		{"", false},
+		{"1cb", false},
+		{"cb()", false},
+		{"alert(1);cb", false},
+		{"a..b", false},
+		{"a.", false},
+		{"</script>", false},
+	}
+	for _, tt := range tests {
+		if got := ValidJSONPCallback(tt.callback); got != tt.valid {
+			t.Errorf("ValidJSONPCallback(%q) = %v, want %v", tt.callback, got, tt.valid)
+		}
+	}
+	long := make([]byte, 129)
+	for i := range long {
+		long[i] = 'a'
+	}
+	if ValidJSONPCallback(string(long)) {
+		t.Error("ValidJSONPCallback accepted a callback longer than 128 chars")
+	}
+	if err := (JsonpJSON{"x;alert(1)", 1}).Render(httptest.NewRecorder()); err != ErrInvalidCallback {
+		t.Errorf("Render with an invalid callback = %v, want ErrInvalidCallback", err)
+	}
+}
@@ -9,3 +9,22 @@ type Render interface {
 	// WriteContentType写入自定义ContentType。 // WriteContentType writes custom ContentType.
 	WriteContentType(w http.ResponseWriter)
 }
+
+var (
+	_ Render = JSON{}
+	_ Render = IndentedJSON{}
+	_ Render = SecureJSON{}
+	_ Render = JsonpJSON{}
+	_ Render = AsciiJSON{}
+	_ Render = PureJSON{}
+	_ Render = XML{}
+	_ Render = String{}
+	_ Render = YAML{}
+)
+
+func writeContentType(w http.ResponseWriter, value []string) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = value
+	}
+}
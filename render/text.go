@@ -0,0 +1,35 @@
+package render
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// String 包含给定的接口对象切片及其格式。 String contains the given interface object slice and its format.
+type String struct {
+	Format string
+	Data   []interface{}
+}
+
+var plainContentType = []string{"text/plain; charset=utf-8"}
+
+// Render (String) 写入数据并自定义ContentType。 Render (String) writes data with custom ContentType.
+func (r String) Render(w http.ResponseWriter) error {
+	return WriteString(w, r.Format, r.Data)
+}
+
+// WriteContentType (String) 写入纯文本ContentType。 WriteContentType (String) writes Plain ContentType.
+func (r String) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, plainContentType)
+}
+
+// WriteString 根据其格式写入数据并写入自定义ContentType。 WriteString writes data according to its format and write custom ContentType.
+func WriteString(w http.ResponseWriter, format string, data []interface{}) (err error) {
+	writeContentType(w, plainContentType)
+	if len(data) > 0 {
+		_, err = fmt.Fprintf(w, format, data...)
+		return
+	}
+	_, err = w.Write([]byte(format))
+	return
+}
@@ -0,0 +1,24 @@
+package render
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// XML 包含给定的接口对象。 XML contains the given interface object.
+type XML struct {
+	Data interface{}
+}
+
+var xmlContentType = []string{"application/xml; charset=utf-8"}
+
+// Render (XML) 编码给定的接口对象，并使用自定义ContentType写入数据。 Render (XML) encodes the given interface object and writes data with custom ContentType.
+func (r XML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return xml.NewEncoder(w).Encode(r.Data)
+}
+
+// WriteContentType (XML) 写入XML ContentType。 WriteContentType (XML) writes XML ContentType for response.
+func (r XML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, xmlContentType)
+}
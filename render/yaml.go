@@ -0,0 +1,34 @@
+package render
+
+import (
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML 包含给定的接口对象。 YAML contains the given interface object.
+// 字段名称和选项取自yaml标签，例如 `yaml:"name,omitempty"`，json标签会被忽略。
+// Field names and options are taken from yaml tags, e.g. `yaml:"name,omitempty"`, json tags are ignored.
+type YAML struct {
+	Data interface{}
+}
+
+var yamlContentType = []string{"application/x-yaml; charset=utf-8"}
+
+// Render (YAML) 编组给定的接口对象，并使用自定义ContentType写入数据。 Render (YAML) marshals the given interface object and writes data with custom ContentType.
+func (r YAML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	bytes, err := yaml.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bytes)
+	return err
+}
+
+// WriteContentType (YAML) 写入YAML ContentType。 WriteContentType (YAML) writes YAML ContentType for response.
+func (r YAML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, yamlContentType)
+}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRenderYAML(t *testing.T) {
+	type item struct {
+		Name    string `yaml:"name" json:"json_name"`
+		Count   int    `yaml:"count,omitempty"`
+		Secret  string `yaml:"-"`
+		Untyped string
+	}
+
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"yaml tags", item{Name: "gin", Count: 2, Secret: "x", Untyped: "u"}, "name: gin\ncount: 2\nuntyped: u\n"},
+		{"omitempty", item{Name: "gin"}, "name: gin\nuntyped: \"\"\n"},
+		{"map", map[string]interface{}{"b": 1, "a": []string{"x", "z"}}, "a:\n    - x\n    - z\nb: 1\n"},
+		{"quoted scalar", map[string]string{"v": "yes"}, "v: \"yes\"\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		if err := (YAML{tt.data}).Render(w); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := w.Body.String(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/x-yaml; charset=utf-8" {
+			t.Errorf("%s: Content-Type = %q", tt.name, ct)
+		}
+	}
+}
+
+type failingYAML struct{}
+
+func (failingYAML) MarshalYAML() (interface{}, error) {
+	return nil, errors.New("marshal failed")
+}
+
+func TestRenderYAMLError(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := (YAML{failingYAML{}}).Render(w); err == nil {
+		t.Error("expected the marshal error to be returned")
+	}
+}
@@ -1,6 +1,10 @@
 package gin_web
 
 import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 )
 
@@ -9,6 +13,12 @@ const (
 	defaultStatus = http.StatusOK
 )
 
+const (
+	errCopyWriter     = "gin-web: 响应写入器被用于Context副本 response writer used on a Context copy"
+	errReleasedWriter = "gin-web: 响应写入器在请求结束后被使用，请将 c.Copy() 传递给goroutine " +
+		"response writer used after the request finished, pass c.Copy() to goroutines instead"
+)
+
 type ResponesWriter interface {
 	http.ResponseWriter
 	http.Hijacker
@@ -58,3 +68,89 @@ func (w *responesWriter) WriteHeaderNow() {
 func (w *responesWriter) Status() int {
 	return w.status
 }
+
+func (w *responesWriter) WriteHeader(code int) {
+	if code > 0 && w.status != code {
+		if w.Written() {
+			DebugPrint("[警告]标头已经写入 [WARNING] Headers were already written. Wanted to override status code %d with %d", w.status, code)
+			return
+		}
+		w.status = code
+	}
+}
+
+func (w *responesWriter) Write(data []byte) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = w.ResponseWriter.Write(data)
+	w.size += n
+	return
+}
+
+func (w *responesWriter) WriteString(s string) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = io.WriteString(w.ResponseWriter, s)
+	w.size += n
+	return
+}
+
+func (w *responesWriter) Size() int {
+	return w.size
+}
+
+// Hijack 实现http.Hijacker接口，HTTP/2连接无法被劫持 Hijack implements the http.Hijacker interface, HTTP/2 connections can not be hijacked.
+func (w *responesWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("响应不支持劫持 the response does not support hijacking")
+	}
+	if w.size < 0 {
+		w.size = 0
+	}
+	return hijacker.Hijack()
+}
+
+// CloseNotify 实现http.CloseNotifier接口 CloseNotify implements the http.CloseNotifier interface.
+func (w *responesWriter) CloseNotify() <-chan bool {
+	if notifier, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return nil
+}
+
+// Flush 实现http.Flusher接口，它先写入标头，因此在HTTP/1.1和HTTP/2上都能流式传输。 Flush implements the http.Flusher interface,
+// it writes the headers first so that streaming works on both HTTP/1.1 and HTTP/2.
+func (w *responesWriter) Flush() {
+	w.WriteHeaderNow()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Pusher 返回服务器推送的http.Pusher，如果连接不支持推送则返回nil。 Pusher returns the http.Pusher for server push,
+// or nil if the connection does not support it, e.g. HTTP/1.1.
+func (w *responesWriter) Pusher() http.Pusher {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher
+	}
+	return nil
+}
+
+// deadWriter 安装在Context副本和已结束的请求上，任何使用都会以其消息panic。 deadWriter is installed on Context copies and finished requests,
+// any use of it panics with its message instead of failing deep inside net/http.
+type deadWriter string
+
+func (w deadWriter) Header() http.Header {
+	panic(string(w))
+}
+
+func (w deadWriter) Write([]byte) (int, error) {
+	panic(string(w))
+}
+
+func (w deadWriter) WriteHeader(int) {
+	panic(string(w))
+}
+
+func (w deadWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	panic(string(w))
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package gin_web
+
+import (
+	"os"
+	"syscall"
+)
+
+// reopenSignals 是使RotatingFileWriter重新打开其文件的信号 reopenSignals are the signals making a RotatingFileWriter reopen its file.
+var reopenSignals = []os.Signal{syscall.SIGUSR1}
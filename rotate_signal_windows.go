@@ -0,0 +1,6 @@
+package gin_web
+
+import "os"
+
+// reopenSignals 在Windows上为空，因为没有SIGUSR1 reopenSignals is empty on Windows as there is no SIGUSR1.
+var reopenSignals []os.Signal
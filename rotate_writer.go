@@ -0,0 +1,298 @@
+package gin_web
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat 是备份文件名中的时间格式，按字典序排序 backupTimeFormat is the time layout in backup file names, it sorts lexically.
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateConfig 配置RotatingFileWriter RotateConfig configures a RotatingFileWriter.
+type RotateConfig struct {
+	//文件达到该字节数时轮转，0表示不按大小轮转 MaxSize rotates the file when it reaches that many bytes, 0 disables size rotation.
+	MaxSize int64
+	//文件打开时间超过该值后，在下一次写入时轮转，0表示不按时间轮转 Interval rotates the file on the next write once it was opened for that long, 0 disables time rotation.
+	Interval time.Duration
+	//保留的gzip压缩备份数量，0表示全部保留 MaxBackups is the number of gzip compressed backups to keep, 0 keeps them all.
+	MaxBackups int
+	//报告不会从Write返回的错误，例如后台压缩、删除旧备份和失败的轮转， OnError reports the errors which are not returned by Write,
+	//默认写入os.Stderr。它不能写入该写入器本身。 e.g. the background compression, the removal of old backups and the failed rotations,
+	//they are written to os.Stderr by default. It must not write to the writer itself.
+	OnError func(error)
+}
+
+// RotatingFileWriter 是按大小和时间轮转的文件写入器，可用作DefaultWriter和DefaultErrorWriter。 RotatingFileWriter is a file writer rotating
+// on size and time, it can be used as DefaultWriter and DefaultErrorWriter.
+// 它对并发写入是安全的。备份被重命名为 name-20060102T150405.000.ext 并在后台压缩。 It is safe for concurrent writes.
+// Backups are renamed to name-20060102T150405.000.ext and compressed in the background.
+// 在非Windows系统上，收到SIGUSR1时重新打开文件，以便外部工具移动它。 On non Windows systems the file is reopened on SIGUSR1 so that external tools can move it.
+//
+//	w, err := gin_web.NewRotatingFileWriter("/var/log/app/access.log", gin_web.RotateConfig{MaxSize: 100 << 20, MaxBackups: 7})
+//	gin_web.DefaultWriter = w
+type RotatingFileWriter struct {
+	filename string
+	config   RotateConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+
+	millMu  sync.Mutex
+	millWg  sync.WaitGroup
+	signals chan os.Signal
+	done    chan struct{}
+}
+
+// NewRotatingFileWriter 以追加方式打开文件，必要时创建其目录 NewRotatingFileWriter opens the file for appending, creating its directory if needed.
+// 如果之后打开文件失败，例如磁盘已满，下一次Write会重试。 If opening the file fails later on, e.g. on a full disk, the next Write retries.
+func NewRotatingFileWriter(filename string, config RotateConfig) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{filename: filename, config: config, done: make(chan struct{})}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	if len(reopenSignals) > 0 {
+		w.signals = make(chan os.Signal, 1)
+		signal.Notify(w.signals, reopenSignals...)
+		go w.watchSignals()
+	}
+	return w, nil
+}
+
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.size, w.openedAt = f, info.Size(), time.Now()
+	return nil
+}
+
+// reportError 将错误传给OnError，默认写入os.Stderr reportError passes the error to OnError, os.Stderr by default.
+func (w *RotatingFileWriter) reportError(err error) {
+	if w.config.OnError != nil {
+		w.config.OnError(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[GIN_WEB-rotate][ERROR] %s: %v\n", w.filename, err)
+}
+
+func (w *RotatingFileWriter) watchSignals() {
+	for {
+		select {
+		case <-w.signals:
+			if err := w.Reopen(); err != nil {
+				w.reportError(err)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Write 写入文件，如果达到MaxSize或Interval则先轮转。 Write writes to the file, rotating it first if MaxSize or Interval is reached.
+// 单次写入永远不会被拆分到两个文件中。 A single write is never split over two files.
+// 失败的轮转会被报告给OnError，写入继续到可以打开的文件中。 A failed rotation is reported to OnError and the write goes on to whichever file can be opened.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file != nil && w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			w.reportError(err)
+		}
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotatingFileWriter) shouldRotate(n int64) bool {
+	if w.config.MaxSize > 0 && w.size > 0 && w.size+n > w.config.MaxSize {
+		return true
+	}
+	return w.config.Interval > 0 && time.Since(w.openedAt) >= w.config.Interval
+}
+
+// Rotate 立即轮转文件 Rotate rotates the file now.
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	return w.rotate()
+}
+
+// Reopen 关闭并重新打开文件，而不轮转它，例如在外部工具移动它之后。 Reopen closes and reopens the file without rotating it, e.g. after an external tool moved it.
+func (w *RotatingFileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.file != nil {
+		err := w.file.Close()
+		w.file = nil
+		if err != nil {
+			w.reportError(err)
+		}
+	}
+	return w.open()
+}
+
+// Close 关闭文件、停止监听信号，并等待正在压缩的备份完成 Close closes the file, stops listening for signals and waits for backups still being compressed.
+func (w *RotatingFileWriter) Close() error {
+	err := w.close()
+	w.millWg.Wait()
+	return err
+}
+
+func (w *RotatingFileWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.signals != nil {
+		signal.Stop(w.signals)
+	}
+	close(w.done)
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate 在持有w.mu时将当前文件重命名为备份，并打开一个新文件 rotate renames the current file to a backup and opens a new one, w.mu is held.
+// 重命名失败时重新打开原文件；出错时w.file可能为nil，由下一次Write重新打开。 The original file is reopened when the rename fails,
+// w.file may be nil on error so that the next Write opens it again.
+func (w *RotatingFileWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return err
+	}
+	ext := filepath.Ext(w.filename)
+	backup := strings.TrimSuffix(w.filename, ext) + "-" + time.Now().Format(backupTimeFormat) + ext
+	if err := os.Rename(w.filename, backup); err != nil {
+		return errors.Join(err, w.open())
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.millWg.Add(1)
+	go func() {
+		defer w.millWg.Done()
+		w.mill(backup)
+	}()
+	return nil
+}
+
+// mill 压缩备份并删除超过MaxBackups的最旧备份 mill compresses the backup and removes the oldest backups over MaxBackups.
+func (w *RotatingFileWriter) mill(backup string) {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+	if err := compressFile(backup); err != nil {
+		w.reportError(err)
+	}
+	if w.config.MaxBackups <= 0 {
+		return
+	}
+	backups, err := w.backups()
+	if err != nil {
+		w.reportError(err)
+		return
+	}
+	for len(backups) > w.config.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			w.reportError(err)
+		}
+		backups = backups[1:]
+	}
+}
+
+// backups 返回按从旧到新排序的备份文件 backups returns the backup files sorted from oldest to newest.
+func (w *RotatingFileWriter) backups() ([]string, error) {
+	dir := filepath.Dir(w.filename)
+	ext := filepath.Ext(w.filename)
+	prefix := strings.TrimSuffix(filepath.Base(w.filename), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name[len(prefix):], ".gz"), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// compressFile 将文件gzip压缩为 name.gz 并删除原文件 compressFile gzips the file into name.gz and removes the original.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
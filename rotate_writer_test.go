@@ -0,0 +1,154 @@
+package gin_web
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "logs", "access.log")
+	w, err := NewRotatingFileWriter(name, RotateConfig{MaxSize: 100, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				if _, err := w.Write([]byte(strings.Repeat("x", 39) + "\n")); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	//Close等待后台压缩完成 // Close waits for the background compression
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	backups, _ := w.backups()
+	compressed := len(backups) == 2
+	for _, backup := range backups {
+		compressed = compressed && strings.HasSuffix(backup, ".gz")
+	}
+	if !compressed {
+		t.Fatalf("backups = %v, want 2 compressed backups", backups)
+	}
+	if info, err := os.Stat(name); err != nil || info.Size() > 100 {
+		t.Errorf("current file: %v, %v", info, err)
+	}
+}
+
+func TestRotatingFileWriterRecovers(t *testing.T) {
+	dir := t.TempDir()
+	logs := filepath.Join(dir, "logs")
+	name := filepath.Join(logs, "app.log")
+	var mu sync.Mutex
+	var reported []error
+	w, err := NewRotatingFileWriter(name, RotateConfig{OnError: func(err error) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	read := func() string {
+		data, _ := os.ReadFile(name)
+		return string(data)
+	}
+
+	tests := []struct {
+		name    string
+		action  func() error
+		write   string
+		wantErr bool
+		content string
+	}{
+		{"write", func() error { return nil }, "a\n", false, "a\n"},
+		{"rename fails", func() error {
+			os.Remove(name)
+			if err := w.Rotate(); err == nil {
+				return errors.New("Rotate succeeded without the file")
+			}
+			return nil
+		}, "b\n", false, "b\n"},
+		{"reopen after move", func() error {
+			os.Rename(name, name+".moved")
+			return w.Reopen()
+		}, "c\n", false, "c\n"},
+		{"open fails", func() error {
+			os.RemoveAll(logs)
+			os.WriteFile(logs, nil, 0644)
+			if err := w.Reopen(); err == nil {
+				return errors.New("Reopen succeeded without a directory")
+			}
+			return nil
+		}, "lost\n", true, ""},
+		{"write reopens", func() error { return os.Remove(logs) }, "d\n", false, "d\n"},
+	}
+	for _, tt := range tests {
+		if err := tt.action(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if _, err := w.Write([]byte(tt.write)); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Write error %v", tt.name, err)
+		}
+		if got := read(); got != tt.content {
+			t.Errorf("%s: file holds %q, want %q", tt.name, got, tt.content)
+		}
+	}
+
+	//大小轮转时重命名失败会被报告，写入继续 // a failed rename on size rotation is reported and the write goes on
+	w.config.MaxSize = 1
+	os.Remove(name)
+	if _, err := w.Write([]byte("e\n")); err != nil {
+		t.Errorf("Write after a failed rotation: %v", err)
+	}
+	if got := read(); got != "e\n" {
+		t.Errorf("file holds %q after a failed rotation", got)
+	}
+	mu.Lock()
+	if len(reported) != 1 || !errors.Is(reported[0], os.ErrNotExist) {
+		t.Errorf("reported %v, want the failed rename", reported)
+	}
+	mu.Unlock()
+
+	w.Close()
+	if _, err := w.Write([]byte("closed\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Close: %v", err)
+	}
+	if err := w.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Reopen after Close: %v", err)
+	}
+}
+
+func TestRotatingFileWriterRotateErrors(t *testing.T) {
+	dir := t.TempDir()
+	logs := filepath.Join(dir, "logs")
+	w, err := NewRotatingFileWriter(filepath.Join(logs, "app.log"), RotateConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	//重命名和重新打开都失败时两个错误都会返回 // both errors are returned when the rename and the reopen fail
+	os.RemoveAll(logs)
+	os.WriteFile(logs, nil, 0644)
+	err = w.Rotate()
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) || !strings.Contains(err.Error(), "rename") || !strings.Contains(err.Error(), "mkdir") {
+		t.Errorf("Rotate error %v, want the rename and the mkdir errors", err)
+	}
+}
@@ -0,0 +1,163 @@
+package gin_web
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// routeTable 是路由器查找所使用的路由快照。 routeTable is the snapshot of routes the router looks up.
+// 一旦通过 Engine.AddRoute 或 Engine.RemoveRoute 发布，它就不会再被修改， Once published by Engine.AddRoute or Engine.RemoveRoute it is never modified again,
+// 因此正在处理的请求始终看到一致的视图，并且查找无需加锁。 so in-flight requests always see a consistent view and lookups stay lock-free.
+type routeTable struct {
+	trees methodTrees
+	hosts hostTrees
+	names map[string]namedRoute
+}
+
+// routeSwapper 保存当前的路由快照并串行化运行时的修改 routeSwapper holds the current route snapshot and serializes runtime updates.
+type routeSwapper struct {
+	mu    sync.Mutex
+	table atomic.Value // *routeTable
+}
+
+// routes 返回当前的路由快照 routes returns the current route snapshot.
+func (engine *Engine) routes() *routeTable {
+	return engine.swapper.table.Load().(*routeTable)
+}
+
+// methodTrees 返回给定主机模式的树，host为空时返回后备树。 methodTrees returns the trees of the given host pattern, or the fallback trees if host is empty.
+func (t *routeTable) methodTrees(host string) *methodTrees {
+	if host == "" {
+		return &t.trees
+	}
+	return &t.hostTree(host).trees
+}
+
+// clone 返回可以安全修改的浅拷贝，节点树本身被共享，必须在修改前重建。 clone returns a shallow copy that is safe to modify,
+// the node trees themselves are shared and must be rebuilt before being changed.
+func (t *routeTable) clone() *routeTable {
+	c := &routeTable{
+		trees: append(methodTrees(nil), t.trees...),
+		names: make(map[string]namedRoute, len(t.names)),
+	}
+	for _, h := range t.hosts {
+		hc := *h
+		hc.trees = append(methodTrees(nil), h.trees...)
+		c.hosts = append(c.hosts, &hc)
+	}
+	for name, r := range t.names {
+		c.names[name] = r
+	}
+	return c
+}
+
+// routeEntry 是从节点树中收集的单个路由 routeEntry is a single route collected from a node tree.
+type routeEntry struct {
+	path     string
+	handlers HandlersChain
+}
+
+func collectRoutes(path string, n *node, routes []routeEntry) []routeEntry {
+	path += n.path
+	if len(n.handlers) > 0 {
+		routes = append(routes, routeEntry{path: path, handlers: n.handlers})
+	}
+	for _, child := range n.children {
+		routes = collectRoutes(path, child, routes)
+	}
+	return routes
+}
+
+// rebuildTree 从root的路由重建一个新的树，跳过路径为skip的路由，并报告是否找到了它。 rebuildTree builds a new tree from the routes of root,
+// leaving out the route at skip, and reports whether it was found. root itself is left untouched.
+func rebuildTree(root *node, skip string) (*node, bool) {
+	rebuilt := &node{fullPath: "/"}
+	found := false
+	if root == nil {
+		return rebuilt, found
+	}
+	for _, r := range collectRoutes("", root, nil) {
+		if r.path == skip {
+			found = true
+			continue
+		}
+		rebuilt.addRoute(r.path, r.handlers)
+	}
+	return rebuilt, found
+}
+
+// updateRoutes 在锁下复制当前快照，对副本应用update，然后原子地发布它。 updateRoutes copies the current snapshot under the lock,
+// applies update to the copy and atomically publishes it. A panic in update, e.g. a conflicting route, is returned as an error
+// and leaves the published routes unchanged.
+func (engine *Engine) updateRoutes(update func(t *routeTable) error) (err error) {
+	engine.swapper.mu.Lock()
+	defer engine.swapper.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("gin-web: %v", r)
+		}
+	}()
+
+	t := engine.routes().clone()
+	if err = update(t); err != nil {
+		return err
+	}
+	engine.swapper.table.Store(t)
+	return nil
+}
+
+// AddRoute 在服务器运行时注册新的路由。 AddRoute registers a new route while the server is running.
+// 与 Handle 不同，它是并发安全的：路由表被复制、修改并原子地替换， Unlike Handle it is concurrency-safe: the route table is copied, changed and atomically swapped,
+// 因此正在处理的请求保持一致的视图。 so in-flight requests keep a consistent view.
+// 冲突的路由将作为错误返回而不是panic。 A conflicting route is returned as an error instead of a panic.
+func (group *RouterGroup) AddRoute(httpMethod, relativePath string, handlers ...HandlerFunc) error {
+	if httpMethod == "" || len(handlers) == 0 {
+		return fmt.Errorf("gin-web: route %s %q needs a method and at least one handler", httpMethod, relativePath)
+	}
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	chain := group.combineHandlers(handlers)
+
+	err := group.engine.updateRoutes(func(t *routeTable) error {
+		trees := t.methodTrees(group.host)
+		root, _ := rebuildTree(trees.get(httpMethod), "")
+		root.addRoute(absolutePath, chain)
+		trees.set(httpMethod, root)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	debugPrintRoute(group.engine.logger, httpMethod, group.host+absolutePath, chain)
+	return nil
+}
+
+// RemoveRoute 在服务器运行时注销路由，正在处理的请求不受影响。 RemoveRoute unregisters a route while the server is running, in-flight requests are not affected.
+// relativePath 必须与注册时使用的模式相同。 relativePath must be the same pattern the route was registered with.
+// 指向该路由的名称也会被删除。 Names pointing at the route are removed as well.
+// 删除主机的最后一个路由后，该主机的请求回退到不带主机的路由。 Once the last route of a host is removed, requests for that host fall back to the routes without a host.
+func (group *RouterGroup) RemoveRoute(httpMethod, relativePath string) error {
+	absolutePath := group.calculateAbsolutePath(relativePath)
+
+	return group.engine.updateRoutes(func(t *routeTable) error {
+		trees := t.methodTrees(group.host)
+		root, found := rebuildTree(trees.get(httpMethod), absolutePath)
+		if !found {
+			return fmt.Errorf("gin-web: route %s %s%s is not registered", httpMethod, group.host, absolutePath)
+		}
+		if len(root.children) == 0 && root.handlers == nil {
+			root = nil
+		}
+		trees.set(httpMethod, root)
+		//空的主机树会遮蔽后备树，因此删除它 // an empty host tree would shadow the fallback trees, so drop it
+		if group.host != "" && len(*trees) == 0 {
+			t.hosts = t.hosts.remove(group.host)
+		}
+		for name, r := range t.names {
+			if r.host == group.host && r.method == httpMethod && r.path == absolutePath {
+				delete(t.names, name)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,176 @@
+package gin_web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func performRequest(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAddRemoveRoute(t *testing.T) {
+	ok := func(body string) HandlerFunc {
+		return func(c *Context) { c.String(http.StatusOK, body) }
+	}
+	router := New()
+	router.GET("/static", ok("static"))
+	router.HandleNamed("item", "GET", "/items/:id", ok("item"))
+
+	tests := []struct {
+		name    string
+		update  func() error
+		wantErr string
+		path    string
+		code    int
+		body    string
+	}{
+		{"add", func() error { return router.AddRoute("GET", "/dynamic", ok("dynamic")) }, "", "/dynamic", 200, "dynamic"},
+		{"existing routes kept", func() error { return nil }, "", "/static", 200, "static"},
+		{"add conflict", func() error { return router.AddRoute("GET", "/items/:other", ok("x")) }, "conflicts", "/items/1", 200, "item"},
+		{"add duplicate", func() error { return router.AddRoute("GET", "/dynamic", ok("x")) }, "already registered", "/dynamic", 200, "dynamic"},
+		{"add without handler", func() error { return router.AddRoute("GET", "/none") }, "at least one handler", "/none", 404, ""},
+		{"remove", func() error { return router.RemoveRoute("GET", "/dynamic") }, "", "/dynamic", 404, ""},
+		{"remove unknown", func() error { return router.RemoveRoute("GET", "/dynamic") }, "not registered", "/static", 200, "static"},
+		{"remove named", func() error { return router.RemoveRoute("GET", "/items/:id") }, "", "/items/1", 404, ""},
+		{"add to group", func() error { return router.Group("/v1").AddRoute("POST", "/x", ok("v1")) }, "", "/v1/x", 0, ""},
+	}
+	for _, tt := range tests {
+		err := tt.update()
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.wantErr)
+		}
+		if tt.code == 0 {
+			continue
+		}
+		w := performRequest(router, "GET", tt.path)
+		if w.Code != tt.code || tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s: GET %s = %d %q, want %d %q", tt.name, tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+
+	if w := performRequest(router, "POST", "/v1/x"); w.Body.String() != "v1" {
+		t.Errorf("POST /v1/x = %d %q, want v1", w.Code, w.Body.String())
+	}
+	if _, err := router.URLFor("item", "id", 1); err == nil {
+		t.Error("RemoveRoute kept the route name")
+	}
+}
+
+func TestAddRouteKeepsOldSnapshot(t *testing.T) {
+	router := New()
+	router.GET("/a", func(*Context) {})
+	before := router.routes()
+
+	if err := router.AddRoute("GET", "/b", func(*Context) {}); err != nil {
+		t.Fatal(err)
+	}
+	if v := before.trees.get("GET").getValue("/b", nil, false); v.handlers != nil {
+		t.Error("AddRoute modified the published snapshot")
+	}
+	if v := router.routes().trees.get("GET").getValue("/b", nil, false); v.handlers == nil {
+		t.Error("AddRoute did not publish the new route")
+	}
+}
+
+func TestAddRouteConcurrentWithRequests(t *testing.T) {
+	router := New()
+	router.GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if w := performRequest(router, "GET", "/ping"); w.Code != http.StatusOK {
+					t.Errorf("GET /ping = %d during route updates", w.Code)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		path := "/r/" + strings.Repeat("x", i+1)
+		if err := router.AddRoute("GET", path, func(*Context) {}); err != nil {
+			t.Error(err)
+		}
+		if i%2 == 0 {
+			if err := router.RemoveRoute("GET", path); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestAddRouteDebugPrint(t *testing.T) {
+	defer func(fn func(string, string, string, int)) { DebugPrintRouteFunc = fn }(DebugPrintRouteFunc)
+	var printed []string
+	DebugPrintRouteFunc = func(httpMethod, absolutePath, _ string, _ int) {
+		printed = append(printed, httpMethod+" "+absolutePath)
+	}
+
+	router := New()
+	router.GET("/items/:id", func(*Context) {})
+	printed = nil
+	if err := router.AddRoute("GET", "/items/:other", func(*Context) {}); err == nil {
+		t.Fatal("expected a conflict")
+	}
+	if err := router.AddRoute("GET", "/dynamic", func(*Context) {}); err != nil {
+		t.Fatal(err)
+	}
+	if len(printed) != 1 || printed[0] != "GET /dynamic" {
+		t.Errorf("printed %q, want only the added route", printed)
+	}
+}
+
+func TestRemoveRouteDropsEmptyHost(t *testing.T) {
+	ok := func(body string) HandlerFunc {
+		return func(c *Context) { c.String(http.StatusOK, body) }
+	}
+	router := New()
+	router.GET("/x", ok("default"))
+	tenant := router.Host("a.example.com")
+	tenant.GET("/x", ok("host"))
+	tenant.POST("/x", ok("host"))
+
+	get := func() string {
+		req := httptest.NewRequest("GET", "/x", nil)
+		req.Host = "a.example.com"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	if body := get(); body != "host" {
+		t.Fatalf("GET = %q, want host", body)
+	}
+	if err := tenant.RemoveRoute("GET", "/x"); err != nil {
+		t.Fatal(err)
+	}
+	if router.routes().hosts.get("a.example.com") == nil {
+		t.Error("host tree removed while it still has a POST route")
+	}
+	if err := tenant.RemoveRoute("POST", "/x"); err != nil {
+		t.Fatal(err)
+	}
+	if router.routes().hosts.get("a.example.com") != nil {
+		t.Error("empty host tree was kept")
+	}
+	if body := get(); body != "default" {
+		t.Errorf("GET after removing the host routes = %q, want default", body)
+	}
+}
@@ -1,12 +1,18 @@
 package gin_web
 
-import "net/http"
+import (
+	"github.com/sourcecmdb/gin-web/utils"
+	"net/http"
+	"path"
+	"strings"
+)
 
 //RouterGroup在内部用于配置路由器，RouterGroup与  RouterGroup is used internally to configure router, a RouterGroup is associated with
 //前缀和处理程序数组（中间件）。  a prefix and an array of handlers (middleware).
 type RouterGroup struct {
 	Handlers HandlersChain
 	basePath string
+	host     string
 	engine   *Engine
 	root     bool
 }
@@ -14,7 +20,7 @@ type RouterGroup struct {
 // IRoutes定义了所有路由器句柄接口。 IRoutes defines all router handle interface.
 type IRoutes interface {
 	Use(...HandlerFunc) IRoutes
-	Handle(string, string, HandlerFunc) IRoutes
+	Handle(string, string, ...HandlerFunc) IRoutes
 	Any(string, ...HandlerFunc) IRoutes
 	GET(string, ...HandlerFunc) IRoutes
 	POST(string, ...HandlerFunc) IRoutes
@@ -32,16 +38,226 @@ type IRoutes interface {
 //  IRouter定义了所有路由器句柄接口，包括单路由器和组路由器。 IRouter defines all router handle interface includes single and group router.
 type IRouter interface {
 	IRoutes
-	Group(string, ...http.HandlerFunc) *RouterGroup
+	Group(string, ...HandlerFunc) *RouterGroup
 }
 
-func (group *RouterGroup) combineHandlers(handlers HandlersChain) HandlersChain{
-	finalSzie := len(group.Handlers) +len(handlers)
-	if finalSzie >= int(abortIndex){
+var _ IRouter = &RouterGroup{}
+
+// Use将中间件添加到组中，请参见GitHub中的示例代码。 Use adds middleware to the group, see example code in GitHub.
+func (group *RouterGroup) Use(middleware ...HandlerFunc) IRoutes {
+	group.Handlers = append(group.Handlers, middleware...)
+	return group.returnObj()
+}
+
+// Group创建一个新的路由器组。 您应该添加所有具有通用中间件或相同路径前缀的路由。 Group creates a new router group. You should add all the routes that have common middlewares or the same path prefix.
+// 例如，所有使用通用中间件进行授权的路由都可以分组。 For example, all the routes that use a common middleware for authorization could be grouped.
+func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *RouterGroup {
+	return &RouterGroup{
+		Handlers: group.combineHandlers(handlers),
+		basePath: group.calculateAbsolutePath(relativePath),
+		host:     group.host,
+		engine:   group.engine,
+	}
+}
+
+// Host创建一个仅匹配给定主机的新路由器组，例如“ api.example.com”。 Host creates a new router group that only matches the given host, e.g. "api.example.com".
+// 以':'开头的标签匹配任意单个标签，其值保存到c.Params中： A label starting with ':' matches any single label and its value is saved into c.Params:
+//     tenant := router.Host(":tenant.example.com")
+//     tenant.GET("/", func(c *gin_web.Context) { c.Param("tenant") })
+// 匹配主机的请求只会搜索该主机的路由，其他请求使用没有主机的路由。 Requests matching a host only search the routes of that host, other requests use the routes without a host.
+func (group *RouterGroup) Host(pattern string) *RouterGroup {
+	pattern = normalizeHost(pattern)
+	utils.Assert1(pattern != "", "host pattern can not be empty")
+	return &RouterGroup{
+		Handlers: group.combineHandlers(nil),
+		basePath: group.basePath,
+		host:     pattern,
+		engine:   group.engine,
+	}
+}
+
+// BasePath返回路由器组的基本路径。 BasePath returns the base path of router group.
+// 例如，如果v：= router.Group（“ / rest / n / v1 / api”），则v.BasePath（）为“ / rest / n / v1 / api”。 For example, if v := router.Group("/rest/n/v1/api"), v.BasePath() is "/rest/n/v1/api".
+func (group *RouterGroup) BasePath() string {
+	return group.basePath
+}
+
+// ScopedHandler 返回只为该组下的路由服务的http.Handler，其他路径和主机回答404。 ScopedHandler returns a http.Handler serving only the routes
+// under the group, other paths and hosts are answered with 404. It can be used to expose a group on a separate listener, e.g. with RunSystemd.
+// 如果组是用Host创建的，请求的主机必须匹配其模式。 If the group was created with Host, the request host must match its pattern.
+func (group *RouterGroup) ScopedHandler() http.Handler {
+	basePath := group.BasePath()
+	var host *hostTree
+	if group.host != "" {
+		host = newHostTree(group.host)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if p := req.URL.Path; basePath != "/" && p != basePath && !strings.HasPrefix(p, strings.TrimSuffix(basePath, "/")+"/") {
+			http.NotFound(w, req)
+			return
+		}
+		if host != nil {
+			if _, ok := host.match(normalizeHost(req.Host), nil); !ok {
+				http.NotFound(w, req)
+				return
+			}
+		}
+		group.engine.ServeHTTP(w, req)
+	})
+}
+
+func (group *RouterGroup) handle(name, httpMethod, relativePath string, handlers HandlersChain) IRoutes {
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	handlers = group.combineHandlers(handlers)
+	group.engine.addRoute(group.host, httpMethod, absolutePath, handlers)
+	if name != "" {
+		group.engine.routes().addRouteName(name, group.host, httpMethod, absolutePath)
+	}
+	return group.returnObj()
+}
+
+// Handle使用给定的路径和方法注册新的请求句柄和中间件。 Handle registers a new request handle and middleware with the given path and method.
+// 最后一个处理程序应该是真正的处理程序，其他处理程序应该是可以并且应该在不同路由之间共享的中间件。 The last handler should be the real handler, the other ones should be middleware that can and should be shared among different routes.
+// 对于GET，POST，PUT，PATCH和DELETE请求，可以使用各自的快捷功能。 For GET, POST, PUT, PATCH and DELETE requests the respective shortcut functions can be used.
+func (group *RouterGroup) Handle(httpMethod, relativePath string, handlers ...HandlerFunc) IRoutes {
+	utils.Assert1(httpMethod != "" && strings.ToUpper(httpMethod) == httpMethod, "http method "+httpMethod+" is not valid")
+	return group.handle("", httpMethod, relativePath, handlers)
+}
+
+// HandleNamed 与Handle相同，但同时以给定名称注册路由，以便 Engine.URLFor 反向生成其URL。
+// HandleNamed is like Handle but also registers the route under the given name, so that Engine.URLFor can build its URL.
+// 名称在整个Engine中必须是唯一的。 The name must be unique across the whole Engine.
+func (group *RouterGroup) HandleNamed(name, httpMethod, relativePath string, handlers ...HandlerFunc) IRoutes {
+	utils.Assert1(name != "", "route name can not be empty")
+	utils.Assert1(httpMethod != "" && strings.ToUpper(httpMethod) == httpMethod, "http method "+httpMethod+" is not valid")
+	return group.handle(name, httpMethod, relativePath, handlers)
+}
+
+// POST是router.Handle（“ POST”，path，handle）的快捷方式。 POST is a shortcut for router.Handle("POST", path, handle).
+func (group *RouterGroup) POST(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return group.handle("", http.MethodPost, relativePath, handlers)
+}
+
+// GET是router.Handle（“ GET”，path，handle）的快捷方式。 GET is a shortcut for router.Handle("GET", path, handle).
+func (group *RouterGroup) GET(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return group.handle("", http.MethodGet, relativePath, handlers)
+}
+
+// DELETE是router.Handle（“ DELETE”，path，handle）的快捷方式。 DELETE is a shortcut for router.Handle("DELETE", path, handle).
+func (group *RouterGroup) DELETE(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return group.handle("", http.MethodDelete, relativePath, handlers)
+}
+
+// PATCH是router.Handle（“ PATCH”，path，handle）的快捷方式。 PATCH is a shortcut for router.Handle("PATCH", path, handle).
+func (group *RouterGroup) PATCH(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return group.handle("", http.MethodPatch, relativePath, handlers)
+}
+
+// PUT是router.Handle（“ PUT”，path，handle）的快捷方式。 PUT is a shortcut for router.Handle("PUT", path, handle).
+func (group *RouterGroup) PUT(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return group.handle("", http.MethodPut, relativePath, handlers)
+}
+
+// OPTIONS是router.Handle（“ OPTIONS”，path，handle）的快捷方式。 OPTIONS is a shortcut for router.Handle("OPTIONS", path, handle).
+func (group *RouterGroup) OPTIONS(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return group.handle("", http.MethodOptions, relativePath, handlers)
+}
+
+// HEAD是router.Handle（“ HEAD”，path，handle）的快捷方式。 HEAD is a shortcut for router.Handle("HEAD", path, handle).
+func (group *RouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) IRoutes {
+	return group.handle("", http.MethodHead, relativePath, handlers)
+}
+
+// Any注册与所有HTTP方法匹配的路由。 Any registers a route that matches all the HTTP methods.
+// GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE.
+func (group *RouterGroup) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
+	for _, method := range anyMethods {
+		group.handle("", method, relativePath, handlers)
+	}
+	return group.returnObj()
+}
+
+// StaticFile注册单个路由，以便为本地文件系统的单个文件提供服务。 StaticFile registers a single route in order to serve a single file of the local filesystem.
+// router.StaticFile("favicon.ico", "./resources/favicon.ico")
+func (group *RouterGroup) StaticFile(relativePath, filepath string) IRoutes {
+	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
+		panic("提供静态文件时不能使用URL参数 URL parameters can not be used when serving a static file")
+	}
+	handler := func(c *Context) {
+		c.File(filepath)
+	}
+	group.GET(relativePath, handler)
+	group.HEAD(relativePath, handler)
+	return group.returnObj()
+}
+
+// Static从给定的文件系统根目录提供文件。 Static serves files from the given file system root.
+// 在内部使用http.FileServer，因此使用http.NotFound代替路由器的NotFound处理程序。 Internally a http.FileServer is used, therefore http.NotFound is used instead of the Router's NotFound handler.
+// 要使用操作系统的文件系统实现，请使用： To use the operating system's file system implementation, use :
+//     router.Static("/static", "/var/www")
+func (group *RouterGroup) Static(relativePath, root string) IRoutes {
+	return group.StaticFS(relativePath, http.Dir(root))
+}
+
+// StaticFS的工作方式与`Static（）`相同，但是可以使用自定义的`http.FileSystem`代替。 StaticFS works just like `Static()` but a custom `http.FileSystem` can be used instead.
+func (group *RouterGroup) StaticFS(relativePath string, fs http.FileSystem) IRoutes {
+	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
+		panic("提供静态文件夹时不能使用URL参数 URL parameters can not be used when serving a static folder")
+	}
+	handler := group.createStaticHandler(relativePath, fs)
+	urlPattern := path.Join(relativePath, "/*filepath")
+
+	// 注册GET和HEAD处理程序 Register GET and HEAD handlers
+	group.GET(urlPattern, handler)
+	group.HEAD(urlPattern, handler)
+	return group.returnObj()
+}
+
+func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileSystem) HandlerFunc {
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	fileServer := http.StripPrefix(absolutePath, http.FileServer(fs))
+
+	return func(c *Context) {
+		file := c.Param("filepath")
+		// 检查文件是否存在和/或我们是否有权访问它 Check if file exists and/or if we have permission to access it
+		f, err := fs.Open(file)
+		if err != nil {
+			c.Writer.WriteHeader(http.StatusNotFound)
+			c.handlers = group.engine.allNoRoute
+			// 重置索引 Reset index
+			c.index = -1
+			return
+		}
+		f.Close()
+
+		fileServer.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodConnect,
+	http.MethodTrace,
+}
+
+func (group *RouterGroup) combineHandlers(handlers HandlersChain) HandlersChain {
+	finalSzie := len(group.Handlers) + len(handlers)
+	if finalSzie >= int(abortIndex) {
 		panic("too many handlers")
 	}
-	mergedHandlers := make(HandlersChain,finalSzie)
-	copy(mergedHandlers,group.Handlers)
-	copy(mergedHandlers[len(group.Handlers):],handlers)
+	mergedHandlers := make(HandlersChain, finalSzie)
+	copy(mergedHandlers, group.Handlers)
+	copy(mergedHandlers[len(group.Handlers):], handlers)
 	return mergedHandlers
-}
\ No newline at end of file
+}
+
+func (group *RouterGroup) calculateAbsolutePath(relativePath string) string {
+	return joinPaths(group.basePath, relativePath)
+}
+
+func (group *RouterGroup) returnObj() IRoutes {
+	if group.root {
+		return group.engine
+	}
+	return group
+}
@@ -0,0 +1,379 @@
+package gin_web
+
+import (
+	"context"
+	"errors"
+	"github.com/sourcecmdb/gin-web/utils"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout 是Engine.ShutdownTimeout为0时使用的超时 defaultShutdownTimeout is used when Engine.ShutdownTimeout is 0.
+const defaultShutdownTimeout = 30 * time.Second
+
+// LifecycleHook 是在服务器启动或关闭时运行的函数。 LifecycleHook is a function run when the server starts or shuts down.
+// 返回错误会中止启动，或在关闭时由 Engine.Shutdown 返回。 Returning an error aborts the start, or is returned by Engine.Shutdown on shutdown.
+type LifecycleHook func(ctx context.Context) error
+
+// lifecycle 保存钩子和正在运行的服务器 lifecycle holds the hooks and the running servers.
+type lifecycle struct {
+	mu         sync.Mutex
+	onStart    []LifecycleHook
+	onShutdown []LifecycleHook
+	servers    map[*http.Server]struct{}
+	starting   *phase
+	stopping   *phase
+}
+
+// phase 是一次启动或关闭，并发调用者等待它完成并共享其错误 phase is one start or shutdown, concurrent callers wait for it and share its error.
+type phase struct {
+	done chan struct{}
+	err  error
+}
+
+func newPhase() *phase {
+	return &phase{done: make(chan struct{})}
+}
+
+func (p *phase) wait() error {
+	<-p.done
+	return p.err
+}
+
+// OnStart 注册在第一个服务器开始接受连接之前运行的钩子。 OnStart registers a hook run before the first server starts accepting connections.
+// 钩子按注册顺序运行，任何错误都会关闭监听器并由Run*返回。 Hooks run in registration order, any error closes the listener and is returned by Run*.
+func (engine *Engine) OnStart(hooks ...LifecycleHook) {
+	engine.lifecycle.mu.Lock()
+	engine.lifecycle.onStart = append(engine.lifecycle.onStart, hooks...)
+	engine.lifecycle.mu.Unlock()
+}
+
+// OnShutdown 注册在所有服务器停止并且活动请求已完成后运行的钩子。 OnShutdown registers a hook run once every server stopped
+// and the active requests are drained, e.g. to close database pools.
+// 钩子按注册顺序运行，即使其中一个失败。 Hooks run in registration order, even if one of them fails.
+func (engine *Engine) OnShutdown(hooks ...LifecycleHook) {
+	engine.lifecycle.mu.Lock()
+	engine.lifecycle.onShutdown = append(engine.lifecycle.onShutdown, hooks...)
+	engine.lifecycle.mu.Unlock()
+}
+
+// Shutdown 优雅地关闭由Run*启动的所有服务器：停止接受新连接，等待活动请求完成，直到ctx结束，
+// 然后按顺序运行OnShutdown钩子。
+// Shutdown gracefully shuts down every server started by Run*: it stops accepting new connections,
+// waits for the active requests to finish until ctx is done, then runs the OnShutdown hooks in order.
+// 如果ctx在请求完成之前结束，剩余的连接会被强制关闭，ctx的错误被返回。 If ctx is done before the requests finish,
+// the remaining connections are closed forcibly and the error of ctx is returned.
+// 钩子获得自己的上下文，在ShutdownTimeout后过期，因此即使ctx已过期它们也能运行。
+// The hooks get their own context expiring after ShutdownTimeout, so they can still run once ctx expired.
+// 阻塞在Run*中的调用在Shutdown返回后返回nil。 The calls blocked in Run* return nil once Shutdown returned.
+// 并发调用会等待同一次关闭并返回其错误。 Concurrent calls wait for the same shutdown and return its error.
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	l := &engine.lifecycle
+	l.mu.Lock()
+	if p := l.stopping; p != nil {
+		l.mu.Unlock()
+		return p.wait()
+	}
+	servers := make([]*http.Server, 0, len(l.servers))
+	for srv := range l.servers {
+		servers = append(servers, srv)
+	}
+	hooks := l.onShutdown
+	p := newPhase()
+	l.stopping = p
+	l.starting = nil
+	l.mu.Unlock()
+
+	p.err = engine.shutdown(ctx, servers, hooks)
+	l.mu.Lock()
+	l.stopping = nil
+	l.mu.Unlock()
+	close(p.done)
+	return p.err
+}
+
+func (engine *Engine) shutdown(ctx context.Context, servers []*http.Server, hooks []LifecycleHook) error {
+	DebugPrint("正在优雅地关闭 Shutting down %d server(s) gracefully", len(servers))
+	err := stopServers(ctx, servers)
+	if e := engine.runShutdownHooks(hooks); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// stopServers 并发地优雅关闭服务器，ctx结束后强制关闭剩余的连接。 stopServers gracefully shuts the servers down concurrently,
+// the connections still active once ctx is done are closed forcibly.
+func stopServers(ctx context.Context, servers []*http.Server) error {
+	errs := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func(srv *http.Server) {
+			err := srv.Shutdown(ctx)
+			if err != nil && ctx.Err() != nil {
+				//超时后强制关闭仍然活动的连接 // force-close the connections still active after the timeout
+				DebugPrint("[警告]关闭超时，强制关闭连接 [WARNING] Shutdown timed out, closing the remaining connections")
+				srv.Close()
+			}
+			errs <- err
+		}(srv)
+	}
+	var err error
+	for range servers {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// runShutdownHooks 按顺序运行钩子，它们获得在ShutdownTimeout后过期的上下文。 runShutdownHooks runs the hooks in order,
+// they get a context expiring after ShutdownTimeout.
+func (engine *Engine) runShutdownHooks(hooks []LifecycleHook) error {
+	hookCtx, cancel := context.WithTimeout(context.Background(), engine.shutdownTimeout())
+	defer cancel()
+	var err error
+	for _, hook := range hooks {
+		if e := hook(hookCtx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// shutdownServer 在serve的上下文结束时只关闭该次调用的服务器，同一引擎上的其他服务器继续服务。
+// shutdownServer shuts down only the server of one serve call once its context is done, the other servers of the engine keep serving.
+// OnShutdown钩子只在最后一个服务器停止后运行；如果Engine.Shutdown正在进行，则等待它并返回其错误。
+// The OnShutdown hooks run only once the last server stopped; if an Engine.Shutdown is in progress, it is waited for and its error returned.
+func (engine *Engine) shutdownServer(ctx context.Context, srv *http.Server) error {
+	DebugPrint("正在优雅地关闭 Shutting down server gracefully")
+	err := stopServers(ctx, []*http.Server{srv})
+
+	l := &engine.lifecycle
+	l.mu.Lock()
+	delete(l.servers, srv)
+	if p := l.stopping; p != nil {
+		l.mu.Unlock()
+		return p.wait()
+	}
+	if len(l.servers) > 0 {
+		l.mu.Unlock()
+		return err
+	}
+	hooks := l.onShutdown
+	l.starting = nil
+	l.mu.Unlock()
+
+	if e := engine.runShutdownHooks(hooks); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// shutdownTimeout 返回Engine.ShutdownTimeout，为0时返回默认值 shutdownTimeout returns Engine.ShutdownTimeout, or the default if it is 0.
+func (engine *Engine) shutdownTimeout() time.Duration {
+	if engine.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return engine.ShutdownTimeout
+}
+
+// RunContext 与Run类似，但是当ctx结束时，它会在ShutdownTimeout内优雅地关闭服务器。 RunContext is like Run, but gracefully shuts down
+// the server within ShutdownTimeout once ctx is done.
+func (engine *Engine) RunContext(ctx context.Context, addr ...string) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	address := utils.ResolverAddress(addr)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	DebugPrint("监听并提供HTTP服务 Listening and serving HTTP on %s\n", address)
+	return engine.serve(ctx, listener, serveHTTP)
+}
+
+// RunWithSignals 与RunContext类似，但是在收到给定信号之一时优雅地关闭，默认为SIGINT和SIGTERM。
+// RunWithSignals is like RunContext but shuts down gracefully when one of the given signals is received, SIGINT and SIGTERM by default.
+func (engine *Engine) RunWithSignals(signals []os.Signal, addr ...string) error {
+	ctx, stop := signalContext(signals...)
+	defer stop()
+	return engine.RunContext(ctx, addr...)
+}
+
+// signalContext 返回在收到给定信号之一时取消的上下文 signalContext returns a context canceled when one of the given signals is received.
+func signalContext(signals ...os.Signal) (context.Context, context.CancelFunc) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		select {
+		case sig := <-ch:
+			DebugPrint("收到信号 Received signal %s", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(ch)
+		cancel()
+	}
+}
+
+// Serve 在所有给定的监听器上同时为引擎服务。 Serve serves the engine on all the given listeners concurrently.
+// 它是ServeContext(context.Background(), listeners...)的快捷方式。 It is a shortcut for ServeContext(context.Background(), listeners...).
+func (engine *Engine) Serve(listeners ...net.Listener) error {
+	return engine.ServeContext(context.Background(), listeners...)
+}
+
+// ServeContext 在所有给定的监听器上同时为引擎服务，直到ctx结束或其中一个失败。 ServeContext serves the engine on all the given listeners
+// concurrently until ctx is done or one of them fails.
+// 第一个致命错误会优雅地关闭所有监听器并被返回。 The first fatal error gracefully shuts all of them down and is returned.
+// 只有此调用的服务器被关闭，同一引擎上的其他Run*和Serve*调用继续服务。 Only the servers of this call are shut down,
+// other Run* and Serve* calls on the same engine keep serving; the OnShutdown hooks run once the last one stopped.
+// 用ListenerWithConfig包装监听器以覆盖其Engine.ServerConfig。 Wrap a listener with ListenerWithConfig to override Engine.ServerConfig for it.
+func (engine *Engine) ServeContext(ctx context.Context, listeners ...net.Listener) (err error) {
+	defer func() { debugPrintError(err) }()
+	if len(listeners) == 0 {
+		return errors.New("至少需要一个监听器 at least one listener is required")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		var configs []ServerConfig
+		if cl, ok := listener.(*configListener); ok {
+			listener, configs = cl.Listener, []ServerConfig{cl.config}
+		}
+		DebugPrint("监听并提供HTTP服务 Listening and serving HTTP on %s", listener.Addr())
+		go func(listener net.Listener, configs []ServerConfig) {
+			errs <- engine.serve(ctx, listener, serveHTTP, configs...)
+		}(listener, configs)
+	}
+	for range listeners {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+			cancel()
+		}
+	}
+	return err
+}
+
+// configListener 是带有自己ServerConfig的监听器 configListener is a listener with its own ServerConfig.
+type configListener struct {
+	net.Listener
+	config ServerConfig
+}
+
+// ListenerWithConfig 包装监听器，使Serve为它使用被config覆盖的Engine.ServerConfig。
+// ListenerWithConfig wraps the listener so that Serve uses Engine.ServerConfig overridden by config for it.
+func ListenerWithConfig(listener net.Listener, config ServerConfig) net.Listener {
+	return &configListener{Listener: listener, config: config}
+}
+
+// serveHTTP 以纯HTTP方式在监听器上服务 serveHTTP serves plain HTTP on the listener.
+func serveHTTP(srv *http.Server, listener net.Listener) error {
+	return srv.Serve(listener)
+}
+
+// serve 是所有Run*变体的共同生命周期：运行OnStart钩子，在监听器上服务， serve is the lifecycle shared by every Run* variant: it runs the OnStart hooks,
+// serves on the listener and, once ctx is done, shuts down gracefully within ShutdownTimeout.
+// 如果设置了Engine.ProxyProtocol，监听器会被NewProxyProtocolListener包装。 The listener is wrapped by NewProxyProtocolListener if Engine.ProxyProtocol is set.
+// 由Engine.Shutdown关闭的服务器会等待关闭完成并返回nil。 A server closed by Engine.Shutdown waits for the shutdown to complete and returns nil.
+// configs 覆盖此监听器的Engine.ServerConfig。 configs override Engine.ServerConfig for this listener.
+func (engine *Engine) serve(ctx context.Context, listener net.Listener, serve func(*http.Server, net.Listener) error, configs ...ServerConfig) error {
+	if engine.ProxyProtocol != nil {
+		proxyListener, err := NewProxyProtocolListener(listener, *engine.ProxyProtocol)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = proxyListener
+	}
+	srv := engine.NewServer(configs...)
+	if err := engine.start(ctx, srv); err != nil {
+		listener.Close()
+		return err
+	}
+	defer engine.untrack(srv)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serve(srv, listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		engine.waitShutdown()
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), engine.shutdownTimeout())
+		defer cancel()
+		return engine.shutdownServer(shutdownCtx, srv)
+	}
+}
+
+// start 跟踪服务器，并在它是第一个时运行OnStart钩子 start tracks the server and runs the OnStart hooks if it is the first one.
+// 同时启动的其他服务器等待钩子完成。 The other servers starting meanwhile wait for the hooks to complete.
+func (engine *Engine) start(ctx context.Context, srv *http.Server) error {
+	l := &engine.lifecycle
+	l.mu.Lock()
+	if l.servers == nil {
+		l.servers = make(map[*http.Server]struct{})
+	}
+	l.servers[srv] = struct{}{}
+	p := l.starting
+	if p != nil {
+		l.mu.Unlock()
+		if err := p.wait(); err != nil {
+			engine.untrack(srv)
+			return err
+		}
+		return nil
+	}
+	p = newPhase()
+	l.starting = p
+	hooks := l.onStart
+	l.mu.Unlock()
+
+	for _, hook := range hooks {
+		if p.err = hook(ctx); p.err != nil {
+			break
+		}
+	}
+	if p.err != nil {
+		engine.untrack(srv)
+		l.mu.Lock()
+		if l.starting == p {
+			l.starting = nil
+		}
+		l.mu.Unlock()
+	}
+	close(p.done)
+	return p.err
+}
+
+func (engine *Engine) untrack(srv *http.Server) {
+	engine.lifecycle.mu.Lock()
+	delete(engine.lifecycle.servers, srv)
+	engine.lifecycle.mu.Unlock()
+}
+
+// waitShutdown 等待正在进行的Engine.Shutdown完成 waitShutdown waits for an in-progress Engine.Shutdown to complete.
+func (engine *Engine) waitShutdown() {
+	engine.lifecycle.mu.Lock()
+	p := engine.lifecycle.stopping
+	engine.lifecycle.mu.Unlock()
+	if p != nil {
+		<-p.done
+	}
+}
@@ -0,0 +1,132 @@
+package gin_web
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ServerConfig 保存Run*变体构建的http.Server的超时和限制 ServerConfig holds the timeouts and limits of the http.Server built by every Run* variant.
+// 零值字段保持默认值，负的超时表示禁用该超时。 Zero fields keep the default value, a negative timeout disables that timeout.
+type ServerConfig struct {
+	//读取整个请求（包括正文）的最长时间 ReadTimeout is the maximum duration for reading the entire request, including the body.
+	ReadTimeout time.Duration
+	//读取请求头的最长时间，防止slowloris攻击 ReadHeaderTimeout is the maximum duration for reading the request headers, it protects against slowloris.
+	ReadHeaderTimeout time.Duration
+	//写入响应的最长时间 WriteTimeout is the maximum duration before timing out writes of the response.
+	WriteTimeout time.Duration
+	//启用keep-alive时等待下一个请求的最长时间 IdleTimeout is the maximum duration to wait for the next request when keep-alives are enabled.
+	IdleTimeout time.Duration
+	//请求头的最大字节数 MaxHeaderBytes is the maximum number of bytes of the request headers.
+	MaxHeaderBytes int
+	//启用UseH2C时每个HTTP/2连接的最大并发流数，0使用http2的默认值 MaxConcurrentStreams is the maximum number of concurrent streams
+	//per HTTP/2 connection when UseH2C is enabled, 0 uses the http2 default.
+	MaxConcurrentStreams uint32
+	//客户端连接状态改变时调用 ConnState is called when a client connection changes state.
+	ConnState func(net.Conn, http.ConnState)
+	//接受连接和处理程序的错误日志，nil使用log包的标准日志 ErrorLog logs the errors accepting connections and from handlers, nil uses the log package's standard logger.
+	ErrorLog *log.Logger
+	//在服务器构建之后、开始服务之前调用，用于高级调优 Configure is called once the server is built and before it serves, for advanced tuning.
+	Configure func(*http.Server)
+}
+
+// DefaultServerConfig 返回生产环境的安全默认值 DefaultServerConfig returns safe production defaults.
+// 读超时和写超时默认不设置，以免中断大文件上传和流式响应；请求头超时已能防止slowloris。
+// No read or write timeout is set by default so that large uploads and streamed responses are not cut,
+// ReadHeaderTimeout already protects against slowloris. Set ReadTimeout if every request body is known to be small.
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+	}
+}
+
+// merge 返回用override的非零字段覆盖后的配置 merge returns the config with the non-zero fields of override applied.
+// 两个Configure函数都会按顺序运行。 Both Configure funcs are run in order.
+func (config ServerConfig) merge(override ServerConfig) ServerConfig {
+	if override.ReadTimeout != 0 {
+		config.ReadTimeout = override.ReadTimeout
+	}
+	if override.ReadHeaderTimeout != 0 {
+		config.ReadHeaderTimeout = override.ReadHeaderTimeout
+	}
+	if override.WriteTimeout != 0 {
+		config.WriteTimeout = override.WriteTimeout
+	}
+	if override.IdleTimeout != 0 {
+		config.IdleTimeout = override.IdleTimeout
+	}
+	if override.MaxHeaderBytes != 0 {
+		config.MaxHeaderBytes = override.MaxHeaderBytes
+	}
+	if override.MaxConcurrentStreams != 0 {
+		config.MaxConcurrentStreams = override.MaxConcurrentStreams
+	}
+	if override.ConnState != nil {
+		config.ConnState = override.ConnState
+	}
+	if override.ErrorLog != nil {
+		config.ErrorLog = override.ErrorLog
+	}
+	if override.Configure != nil {
+		if configure := config.Configure; configure != nil {
+			config.Configure = func(srv *http.Server) {
+				configure(srv)
+				override.Configure(srv)
+			}
+		} else {
+			config.Configure = override.Configure
+		}
+	}
+	return config
+}
+
+// NewServer 返回使用Engine.ServerConfig为引擎服务的http.Server NewServer returns a http.Server serving the engine with Engine.ServerConfig,
+// 给定的配置按顺序覆盖它。它可用于自行管理服务器。 overridden in order by the given configs. It can be used to manage the server yourself.
+func (engine *Engine) NewServer(overrides ...ServerConfig) *http.Server {
+	config := engine.ServerConfig
+	for _, override := range overrides {
+		config = config.merge(override)
+	}
+	srv := &http.Server{
+		Handler:           engine,
+		ReadTimeout:       positiveDuration(config.ReadTimeout),
+		ReadHeaderTimeout: positiveDuration(config.ReadHeaderTimeout),
+		WriteTimeout:      positiveDuration(config.WriteTimeout),
+		IdleTimeout:       positiveDuration(config.IdleTimeout),
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+		ConnState:         config.ConnState,
+		ErrorLog:          config.ErrorLog,
+	}
+	if srv.MaxHeaderBytes < 0 {
+		srv.MaxHeaderBytes = 0
+	}
+	if config.Configure != nil {
+		config.Configure(srv)
+	}
+	//h2c在Configure之后应用，使用最终的超时和处理程序 // h2c is applied after Configure, with the final timeouts and handler
+	if engine.UseH2C {
+		srv.Handler = h2cHandler(srv, config)
+	}
+	return srv
+}
+
+// RunListenerWithConfig 与RunListener类似，但是用给定配置覆盖该监听器的Engine.ServerConfig。
+// RunListenerWithConfig is like RunListener but overrides Engine.ServerConfig for this listener with the given config.
+func (engine *Engine) RunListenerWithConfig(listener net.Listener, config ServerConfig) (err error) {
+	DebugPrint("在侦听器上侦听和服务HTTP与address @％s绑定的内容 Listening and serving HTTP on listener what's bind with address@%s", listener.Addr())
+	defer func() { debugPrintError(err) }()
+	err = engine.serve(context.Background(), listener, serveHTTP, config)
+	return
+}
+
+// positiveDuration 将负的超时转换为0，即无超时 positiveDuration turns a negative timeout into 0, i.e. no timeout.
+func positiveDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
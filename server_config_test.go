@@ -0,0 +1,95 @@
+package gin_web
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+func TestNewServerConfig(t *testing.T) {
+	router := New()
+	var configured []string
+
+	tests := []struct {
+		name      string
+		overrides []ServerConfig
+		check     func(srv *http.Server) bool
+	}{
+		{"defaults", nil, func(srv *http.Server) bool {
+			return srv.ReadTimeout == 0 && srv.WriteTimeout == 0 &&
+				srv.ReadHeaderTimeout == 10*time.Second && srv.IdleTimeout == 120*time.Second &&
+				srv.MaxHeaderBytes == http.DefaultMaxHeaderBytes
+		}},
+		{"override", []ServerConfig{{ReadTimeout: time.Minute}}, func(srv *http.Server) bool {
+			return srv.ReadTimeout == time.Minute && srv.ReadHeaderTimeout == 10*time.Second
+		}},
+		{"negative disables", []ServerConfig{{IdleTimeout: -1, MaxHeaderBytes: -1}}, func(srv *http.Server) bool {
+			return srv.IdleTimeout == 0 && srv.MaxHeaderBytes == 0
+		}},
+		{"later override wins", []ServerConfig{{WriteTimeout: time.Second}, {WriteTimeout: 2 * time.Second}}, func(srv *http.Server) bool {
+			return srv.WriteTimeout == 2*time.Second
+		}},
+		{"configure chained", []ServerConfig{
+			{Configure: func(*http.Server) { configured = append(configured, "a") }},
+			{Configure: func(*http.Server) { configured = append(configured, "b") }},
+		}, func(*http.Server) bool {
+			return len(configured) == 2 && configured[0] == "a" && configured[1] == "b"
+		}},
+	}
+	for _, tt := range tests {
+		if srv := router.NewServer(tt.overrides...); !tt.check(srv) {
+			t.Errorf("%s: unexpected server %+v", tt.name, srv)
+		}
+	}
+}
+
+func TestNewServerH2C(t *testing.T) {
+	router := New()
+	router.UseH2C = true
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, "engine") })
+	replaced := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, req.Proto+" replaced")
+	})
+	srv := router.NewServer(ServerConfig{
+		IdleTimeout: 100 * time.Millisecond,
+		Configure:   func(srv *http.Server) { srv.Handler = replaced },
+	})
+	listener := listen(t)
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	var dials int32
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	get := func() string {
+		resp, err := client.Get("http://" + listener.Addr().String() + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	if body := get(); body != "HTTP/2.0 replaced" {
+		t.Fatalf("got %q, want the replaced handler over HTTP/2", body)
+	}
+	//空闲超时后连接应被关闭，下一个请求需要重新拨号 // the connection is closed after the idle timeout, the next request dials again
+	time.Sleep(300 * time.Millisecond)
+	get()
+	if n := atomic.LoadInt32(&dials); n != 2 {
+		t.Errorf("dialed %d times, want the idle HTTP/2 connection closed after IdleTimeout", n)
+	}
+}
@@ -0,0 +1,201 @@
+package gin_web
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return listener
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	router := New()
+	var mu sync.Mutex
+	var order []string
+	hook := func(name string) LifecycleHook {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+	router.OnStart(hook("start"))
+	router.OnShutdown(hook("stop1"), hook("stop2"))
+	router.GET("/slow", func(c *Context) {
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+
+	l1, l2 := listen(t), listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	res := make(chan error, 1)
+	go func() {
+		res <- router.ServeContext(ctx, l1, ListenerWithConfig(l2, ServerConfig{IdleTimeout: time.Second}))
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + l2.Addr().String() + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if b := <-body; b != "done" {
+		t.Errorf("in-flight request was not drained: %q", b)
+	}
+	if err := <-res; err != nil {
+		t.Errorf("ServeContext() = %v", err)
+	}
+	if want := []string{"start", "stop1", "stop2"}; len(order) != 3 || order[0] != want[0] || order[1] != want[1] || order[2] != want[2] {
+		t.Errorf("hook order = %v, want %v", order, want)
+	}
+}
+
+func TestServeContextShutsDownOwnServers(t *testing.T) {
+	router := New()
+	var mu sync.Mutex
+	stopped := 0
+	router.OnShutdown(func(context.Context) error {
+		mu.Lock()
+		stopped++
+		mu.Unlock()
+		return nil
+	})
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+
+	l1, l2 := listen(t), listen(t)
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	res1, res2 := make(chan error, 1), make(chan error, 1)
+	go func() { res1 <- router.ServeContext(ctx1, l1) }()
+	go func() { res2 <- router.ServeContext(ctx2, l2) }()
+	time.Sleep(50 * time.Millisecond)
+
+	get := func(l net.Listener) error {
+		client := &http.Client{Timeout: time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+		resp, err := client.Get("http://" + l.Addr().String() + "/")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	cancel1()
+	if err := <-res1; err != nil {
+		t.Errorf("first ServeContext() = %v", err)
+	}
+	if err := get(l1); err == nil {
+		t.Error("the canceled ServeContext still serves")
+	}
+	if err := get(l2); err != nil {
+		t.Errorf("the other ServeContext stopped serving: %v", err)
+	}
+	mu.Lock()
+	if stopped != 0 {
+		t.Errorf("OnShutdown ran %d times while a server was still running", stopped)
+	}
+	mu.Unlock()
+
+	cancel2()
+	if err := <-res2; err != nil {
+		t.Errorf("second ServeContext() = %v", err)
+	}
+	mu.Lock()
+	if stopped != 1 {
+		t.Errorf("OnShutdown ran %d times after the last server stopped, want 1", stopped)
+	}
+	mu.Unlock()
+}
+
+func TestStartHookError(t *testing.T) {
+	router := New()
+	router.OnStart(func(context.Context) error { return errors.New("boom") })
+	if err := router.RunListener(listen(t)); err == nil || err.Error() != "boom" {
+		t.Errorf("RunListener() = %v, want boom", err)
+	}
+}
+
+func TestShutdownTimeoutForcesClose(t *testing.T) {
+	router := New()
+	router.ShutdownTimeout = time.Second
+	var hookErr error
+	router.OnShutdown(func(ctx context.Context) error {
+		hookErr = ctx.Err()
+		return nil
+	})
+	//处理程序退出时发出信号，测试在返回之前等待它 // the handler signals its exit and the test waits for it before returning
+	handlerDone := make(chan struct{})
+	router.GET("/stuck", func(c *Context) {
+		defer close(handlerDone)
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	listener := listen(t)
+	res := make(chan error, 1)
+	go func() { res <- router.RunListener(listener) }()
+	time.Sleep(50 * time.Millisecond)
+
+	clientErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/stuck")
+		if err == nil {
+			resp.Body.Close()
+		}
+		clientErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := router.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if hookErr != nil {
+		t.Errorf("OnShutdown hook got an expired context: %v", hookErr)
+	}
+	select {
+	case err := <-clientErr:
+		if err == nil {
+			t.Error("the stuck request was answered instead of being closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("the stuck connection was not closed after the shutdown timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown took %v", elapsed)
+	}
+	select {
+	case <-handlerDone:
+	case <-time.After(time.Second):
+		t.Error("the stuck handler kept running after its connection was closed")
+	}
+	if err := <-res; err != nil {
+		t.Errorf("RunListener() = %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package gin_web
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// debugLogger 保存SetDebugLogger设置的*slog.Logger debugLogger holds the *slog.Logger set by SetDebugLogger.
+var debugLogger atomic.Value
+
+// SetDebugLogger 设置DebugPrint和调试错误使用的slog日志记录器，它们以Debug和Error级别写入。 SetDebugLogger sets the slog logger used by
+// DebugPrint and the debug errors, they are written at the Debug and Error levels. nil restores DefaultWriter and DefaultErrorWriter.
+// 它是包级别的，由所有引擎共享；只有路由消息使用Engine.SetLogger。 It is package level and shared by every engine, only the route messages use Engine.SetLogger.
+func SetDebugLogger(logger *slog.Logger) {
+	debugLogger.Store(logger)
+}
+
+// SetLogger 设置引擎的slog日志记录器。 SetLogger sets the slog logger of the engine.
+// 设置后，Logger和Recovery中间件以及该引擎注册的路由消息都写入它，而不是DefaultWriter和DefaultErrorWriter， Once set, the Logger and Recovery middleware
+// and the routes registered on this engine are written to it instead of DefaultWriter and DefaultErrorWriter, with consistent levels and attributes.
+// 它不影响其他引擎，包级别的调试消息请使用SetDebugLogger。nil恢复默认输出。 Other engines are not affected, use SetDebugLogger for the package level
+// debug messages. nil restores the default output.
+func (engine *Engine) SetLogger(logger *slog.Logger) {
+	engine.logger = logger
+}
+
+// Logger 返回SetLogger设置的slog日志记录器，如果没有则返回nil Logger returns the slog logger set by SetLogger, nil if there is none.
+func (engine *Engine) Logger() *slog.Logger {
+	return engine.logger
+}
+
+func loadDebugLogger() *slog.Logger {
+	logger, _ := debugLogger.Load().(*slog.Logger)
+	return logger
+}
+
+// slogDebug 将调试消息以Debug级别写入slog slogDebug writes a debug message to slog at the Debug level.
+func slogDebug(logger *slog.Logger, format string, values []interface{}, attrs ...slog.Attr) {
+	msg := strings.TrimSpace(fmt.Sprintf(format, values...))
+	logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
+
+// accessLogLevel 返回请求日志的级别：5xx为Error，4xx为Warn，其他为Info accessLogLevel returns the level of an access log: Error for 5xx, Warn for 4xx, Info otherwise.
+func accessLogLevel(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// slogAccess 将访问日志写入slog slogAccess writes an access log to slog.
+func slogAccess(logger *slog.Logger, param LogFormatterParam) {
+	attrs := []slog.Attr{
+		slog.Int("status", param.StatusCode),
+		slog.String("method", param.Method),
+		slog.String("path", param.Path),
+		slog.Duration("latency", param.Latency),
+		slog.String("client_ip", param.ClientIP),
+		slog.Int("body_size", param.BodySize),
+	}
+	if param.RequestID != "" {
+		attrs = append(attrs, slog.String("request_id", param.RequestID))
+	}
+	if param.ErrorMessge != "" {
+		attrs = append(attrs, slog.String("error", strings.TrimSpace(param.ErrorMessge)))
+	}
+	ctx := context.Background()
+	if param.Request != nil {
+		ctx = param.Request.Context()
+	}
+	logger.LogAttrs(ctx, accessLogLevel(param.StatusCode), "request", attrs...)
+}
+
+// slogRecovery 将恢复的恐慌以Error级别写入slog，Authorization标头被隐藏 slogRecovery writes a recovered panic to slog at the Error level,
+// the Authorization header is masked.
+func slogRecovery(logger *slog.Logger, req *http.Request, recovered interface{}, brokenPipe bool) {
+	attrs := []slog.Attr{
+		slog.String("panic", fmt.Sprint(recovered)),
+		slog.Bool("broken_pipe", brokenPipe),
+	}
+	if req != nil {
+		attrs = append(attrs, slog.String("method", req.Method), slog.String("path", req.URL.Path))
+		if IsDebugging() {
+			headers := make([]string, 0, len(req.Header))
+			for name, values := range req.Header {
+				if name == "Authorization" {
+					values = []string{"*"}
+				}
+				headers = append(headers, name+": "+strings.Join(values, ", "))
+			}
+			sort.Strings(headers)
+			attrs = append(attrs, slog.Any("headers", headers))
+		}
+	}
+	if !brokenPipe {
+		attrs = append(attrs, slog.String("stack", string(stack(4))))
+	}
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	logger.LogAttrs(ctx, slog.LevelError, "panic recovered", attrs...)
+}
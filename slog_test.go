@@ -0,0 +1,78 @@
+package gin_web
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestSlog(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestSetLogger(t *testing.T) {
+	previous := Mode()
+	t.Cleanup(func() { SetMode(previous) })
+	SetMode(DebugMode)
+
+	var first, second bytes.Buffer
+	a, b := New(), New()
+	a.SetLogger(newTestSlog(&first))
+	b.SetLogger(newTestSlog(&second))
+	a.Use(Logger(), Recovery())
+	a.GET("/a", func(c *Context) { c.String(http.StatusOK, "a") })
+	a.GET("/boom", func(c *Context) { panic("kaboom") })
+	b.GET("/b", func(c *Context) { c.String(http.StatusOK, "b") })
+	a.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a", nil))
+	a.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/boom", nil))
+
+	tests := []struct {
+		name    string
+		out     string
+		want    string
+		present bool
+	}{
+		{"own routes", first.String(), `"msg":"route registered","method":"GET","path":"/a"`, true},
+		{"other engine routes", first.String(), `"path":"/b"`, false},
+		{"second engine routes", second.String(), `"path":"/b"`, true},
+		{"second engine not mixed", second.String(), `"path":"/a"`, false},
+		{"access log", first.String(), `"level":"INFO","msg":"request","status":200`, true},
+		{"panic", first.String(), `"msg":"panic recovered","panic":"kaboom"`, true},
+		{"panic access log", first.String(), `"level":"ERROR","msg":"request","status":500`, true},
+	}
+	for _, tt := range tests {
+		if strings.Contains(tt.out, tt.want) != tt.present {
+			t.Errorf("%s: %q present = %v, want %v in\n%s", tt.name, tt.want, !tt.present, tt.present, tt.out)
+		}
+	}
+}
+
+func TestSetDebugLogger(t *testing.T) {
+	previous := Mode()
+	t.Cleanup(func() { SetMode(previous) })
+	SetMode(DebugMode)
+	defer SetDebugLogger(nil)
+
+	var debug, engine bytes.Buffer
+	SetDebugLogger(newTestSlog(&debug))
+	DebugPrint("hello %s\n", "world")
+	debugPrintError(errors.New("failed"))
+
+	withLogger := New()
+	withLogger.SetLogger(newTestSlog(&engine))
+	withLogger.GET("/own", func(*Context) {})
+	New().GET("/fallback", func(*Context) {})
+
+	for _, want := range []string{`"level":"DEBUG","msg":"hello world"`, `"level":"ERROR","msg":"failed"`, `"path":"/fallback"`} {
+		if !strings.Contains(debug.String(), want) {
+			t.Errorf("debug logger misses %q in\n%s", want, debug.String())
+		}
+	}
+	if strings.Contains(debug.String(), `"path":"/own"`) || !strings.Contains(engine.String(), `"path":"/own"`) {
+		t.Errorf("route of an engine with a logger went to the debug logger:\n%s", debug.String())
+	}
+}
@@ -0,0 +1,117 @@
+package gin_web
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// systemdListenFdsStart 是systemd传递的第一个文件描述符 systemdListenFdsStart is the first file descriptor passed by systemd.
+const systemdListenFdsStart = 3
+
+// SystemdListener 是systemd套接字激活传递的监听器 SystemdListener is a listener passed by systemd socket activation.
+// Name 来自套接字单元的FileDescriptorName=，默认为单元名。 Name comes from FileDescriptorName= of the socket unit, the unit name by default.
+type SystemdListener struct {
+	net.Listener
+	Name string
+}
+
+// SystemdListeners 返回systemd通过LISTEN_FDS、LISTEN_PID和LISTEN_FDNAMES传递的监听器，
+// 并取消设置这些变量，使子进程不会继承它们。如果进程不是由套接字激活启动的，则返回nil。
+// SystemdListeners returns the listeners passed by systemd through LISTEN_FDS, LISTEN_PID and LISTEN_FDNAMES
+// and unsets those variables so that children do not inherit them. It returns nil if the process was not socket activated.
+func SystemdListeners() ([]SystemdListener, error) {
+	pid, fds, fdNames := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES")
+	if fds == "" {
+		return nil, nil
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+		return nil, fmt.Errorf("LISTEN_PID=%q与当前进程%d不匹配 LISTEN_PID=%q does not match the current process %d", pid, os.Getpid(), pid, os.Getpid())
+	}
+	count, err := strconv.Atoi(fds)
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("无效的 invalid LISTEN_FDS=%q", fds)
+	}
+	var names []string
+	if fdNames != "" {
+		names = strings.Split(fdNames, ":")
+	}
+
+	listeners := make([]SystemdListener, 0, count)
+	for i := 0; i < count; i++ {
+		fd := systemdListenFdsStart + i
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(fd), name)
+		listener, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("套接字 socket %q (fd %d): %v", name, fd, err)
+		}
+		listeners = append(listeners, SystemdListener{Listener: listener, Name: name})
+	}
+	return listeners, nil
+}
+
+// RunSystemd 在systemd套接字激活传递的所有套接字上服务，直到收到SIGINT或SIGTERM。 RunSystemd serves on every socket passed by systemd
+// socket activation until SIGINT or SIGTERM is received.
+// handlers 将套接字名称映射到为其服务的处理程序，例如另一个*Engine或RouterGroup.ScopedHandler()， handlers maps socket names to the handler serving them,
+// 没有条目的套接字由引擎提供服务。 e.g. another *Engine or RouterGroup.ScopedHandler(), the sockets without an entry are served by engine.
+// 一个名称可以对应多个套接字，例如同时监听IPv4和IPv6的单元，它们都由同一个处理程序服务。
+// A name may cover several sockets, e.g. a unit listening on both IPv4 and IPv6, all of them are served by the same handler.
+func (engine *Engine) RunSystemd(handlers ...map[string]http.Handler) (err error) {
+	defer func() { debugPrintError(err) }()
+
+	systemdListeners, err := SystemdListeners()
+	if err != nil {
+		return err
+	}
+	if len(systemdListeners) == 0 {
+		return errors.New("没有systemd传递的套接字 no sockets passed by systemd")
+	}
+
+	named := make(map[string]http.Handler)
+	for _, m := range handlers {
+		for name, handler := range m {
+			named[name] = handler
+		}
+	}
+	listeners := make([]net.Listener, 0, len(systemdListeners))
+	used := make(map[string]bool, len(named))
+	for _, sl := range systemdListeners {
+		DebugPrint("systemd套接字 systemd socket %q on %s", sl.Name, sl.Addr())
+		handler, ok := named[sl.Name]
+		if !ok {
+			listeners = append(listeners, sl.Listener)
+			continue
+		}
+		used[sl.Name] = true
+		listeners = append(listeners, ListenerWithConfig(sl.Listener, ServerConfig{
+			Configure: func(srv *http.Server) { srv.Handler = handler },
+		}))
+	}
+	for name := range named {
+		if !used[name] {
+			closeListeners(listeners)
+			return fmt.Errorf("systemd没有传递名为%q的套接字 no socket named %q was passed by systemd", name, name)
+		}
+	}
+
+	ctx, stop := signalContext(os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return engine.ServeContext(ctx, listeners...)
+}
@@ -0,0 +1,153 @@
+//go:build !windows
+
+package gin_web
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// systemdHelperEnv 让测试二进制文件作为套接字激活的服务器运行 systemdHelperEnv makes the test binary run as a socket activated server.
+const systemdHelperEnv = "GIN_WEB_TEST_SYSTEMD"
+
+// TestSystemdHelperProcess 不是真正的测试，它是TestRunSystemd启动的服务器进程。 TestSystemdHelperProcess isn't a real test,
+// it is the server process started by TestRunSystemd with the sockets passed like systemd does.
+func TestSystemdHelperProcess(t *testing.T) {
+	if os.Getenv(systemdHelperEnv) == "" {
+		t.Skip("helper process for TestRunSystemd")
+	}
+	//systemd在exec之前设置LISTEN_PID // systemd sets LISTEN_PID right before exec
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	router := New()
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, "engine") })
+	api := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { io.WriteString(w, "api") })
+	if err := router.RunSystemd(map[string]http.Handler{"api": api}); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestRunSystemd(t *testing.T) {
+	names := []string{"api", "api", "web"}
+	var files []*os.File
+	var addrs []string
+	for range names {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := listener.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatal(err)
+		}
+		listener.Close()
+		defer f.Close()
+		files = append(files, f)
+		addrs = append(addrs, listener.Addr().String())
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSystemdHelperProcess$")
+	cmd.Env = append(os.Environ(), systemdHelperEnv+"=1", "LISTEN_FDS=3", "LISTEN_FDNAMES=api:api:web")
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Process.Kill()
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	for i, addr := range addrs {
+		want := map[string]string{"api": "api", "web": "engine"}[names[i]]
+		resp, err := client.Get("http://" + addr + "/")
+		if err != nil {
+			t.Fatalf("socket %d (%s): %v", i, names[i], err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != want {
+			t.Errorf("socket %d (%s) served %q, want %q", i, names[i], body, want)
+		}
+	}
+
+	cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case err := <-exited:
+		if err != nil {
+			t.Errorf("server exited with %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("server did not exit after SIGTERM")
+	}
+}
+
+func TestSystemdListenersEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"not activated", map[string]string{}, false},
+		{"other pid", map[string]string{"LISTEN_PID": "1", "LISTEN_FDS": "1"}, true},
+		{"bad count", map[string]string{"LISTEN_PID": strconv.Itoa(os.Getpid()), "LISTEN_FDS": "x"}, true},
+	}
+	for _, tt := range tests {
+		for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+			os.Unsetenv(key)
+		}
+		for key, value := range tt.env {
+			os.Setenv(key, value)
+		}
+		listeners, err := SystemdListeners()
+		if (err != nil) != tt.wantErr || listeners != nil {
+			t.Errorf("%s: SystemdListeners() = %v, %v", tt.name, listeners, err)
+		}
+		if os.Getenv("LISTEN_FDS") != "" {
+			t.Errorf("%s: LISTEN_FDS was not unset", tt.name)
+		}
+	}
+}
+
+func TestScopedHandler(t *testing.T) {
+	router := New()
+	ok := func(c *Context) { c.String(http.StatusOK, c.Request.URL.Path) }
+	router.GET("/public", ok)
+	v1 := router.Group("/v1")
+	v1.GET("/users", ok)
+	tenant := router.Host(":tenant.example.com").Group("/admin")
+	tenant.GET("/", ok)
+
+	tests := []struct {
+		handler http.Handler
+		host    string
+		path    string
+		code    int
+	}{
+		{v1.ScopedHandler(), "", "/v1/users", http.StatusOK},
+		{v1.ScopedHandler(), "", "/public", http.StatusNotFound},
+		{v1.ScopedHandler(), "", "/v1x", http.StatusNotFound},
+		{tenant.ScopedHandler(), "acme.example.com", "/admin/", http.StatusOK},
+		{tenant.ScopedHandler(), "other.org", "/admin/", http.StatusNotFound},
+		{tenant.ScopedHandler(), "acme.example.com", "/public", http.StatusNotFound},
+		{router.ScopedHandler(), "", "/public", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.host != "" {
+			req.Host = tt.host
+		}
+		w := httptest.NewRecorder()
+		tt.handler.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s%s: got %d, want %d", tt.host, tt.path, w.Code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package gin_web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloadInterval 是CertReloader检查证书文件更改的默认间隔 CertReloadInterval is the default interval at which a CertReloader checks the certificate files for changes.
+var CertReloadInterval = 10 * time.Second
+
+// CertPair 是证书文件和私钥文件对 CertPair is a certificate file and key file pair.
+type CertPair struct {
+	CertFile string
+	KeyFile  string
+}
+
+// reloadingCert 是一个证书对及其已加载的证书 reloadingCert is a certificate pair and its loaded certificate.
+type reloadingCert struct {
+	pair    CertPair
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// CertReloader 提供证书对，当文件在磁盘上发生变化时重新加载它们，并按SNI选择证书。 CertReloader serves certificate pairs, reloads them when
+// the files change on disk and selects the certificate by SNI.
+// 重新加载失败时保留之前的证书。 The previous certificate is kept when a reload fails.
+type CertReloader struct {
+	mu    sync.RWMutex
+	certs []*reloadingCert
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// NewCertReloader 加载给定的证书对，并每隔interval轮询它们的更改，interval为0时使用CertReloadInterval。
+// NewCertReloader loads the given certificate pairs and polls them for changes every interval, CertReloadInterval if interval is 0.
+// 第一个证书对在客户端没有发送匹配的SNI时使用。 The first pair is used when the client sends no matching SNI.
+func NewCertReloader(interval time.Duration, pairs ...CertPair) (*CertReloader, error) {
+	if len(pairs) == 0 {
+		return nil, errors.New("至少需要一个证书对 at least one certificate pair is required")
+	}
+	if interval <= 0 {
+		interval = CertReloadInterval
+	}
+	r := &CertReloader{stop: make(chan struct{})}
+	for _, pair := range pairs {
+		c := &reloadingCert{pair: pair}
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+		r.certs = append(r.certs, c)
+	}
+	go r.watch(interval)
+	return r, nil
+}
+
+// modTime 返回证书文件和私钥文件中较新的修改时间 modTime returns the latest modification time of the certificate and key files.
+func (pair CertPair) modTime() (time.Time, error) {
+	certInfo, err := os.Stat(pair.CertFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	keyInfo, err := os.Stat(pair.KeyFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if keyInfo.ModTime().After(certInfo.ModTime()) {
+		return keyInfo.ModTime(), nil
+	}
+	return certInfo.ModTime(), nil
+}
+
+func (c *reloadingCert) load() error {
+	modTime, err := c.pair.modTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.pair.CertFile, c.pair.KeyFile)
+	if err != nil {
+		return err
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return err
+		}
+	}
+	c.cert, c.modTime = &cert, modTime
+	return nil
+}
+
+func (r *CertReloader) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.reload()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// reload 重新加载修改过的证书对 reload reloads the modified certificate pairs.
+func (r *CertReloader) reload() {
+	for i := range r.certs {
+		r.mu.RLock()
+		c := *r.certs[i]
+		r.mu.RUnlock()
+
+		modTime, err := c.pair.modTime()
+		if err != nil || modTime.Equal(c.modTime) {
+			continue
+		}
+		if err := c.load(); err != nil {
+			//文件可能只写了一半，保留旧证书并在下次重试 // the files may be half written, keep the old certificate and retry next time
+			DebugPrint("[警告]无法重新加载证书 [WARNING] Could not reload certificate %s: %v", c.pair.CertFile, err)
+			continue
+		}
+		DebugPrint("已重新加载证书 Reloaded certificate %s", c.pair.CertFile)
+		r.mu.Lock()
+		r.certs[i] = &c
+		r.mu.Unlock()
+	}
+}
+
+// GetCertificate 返回与客户端SNI匹配的证书，否则返回第一个证书。它可用作tls.Config.GetCertificate。
+// GetCertificate returns the certificate matching the client SNI, the first one otherwise. It can be used as tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if hello.ServerName != "" {
+		for _, c := range r.certs {
+			if c.cert.Leaf.VerifyHostname(hello.ServerName) == nil {
+				return c.cert, nil
+			}
+		}
+	}
+	return r.certs[0].cert, nil
+}
+
+// TLSConfig 返回使用重新加载器选择证书的tls.Config TLSConfig returns a tls.Config selecting the certificates with the reloader.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Close 停止轮询证书文件 Close stops polling the certificate files.
+func (r *CertReloader) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	return nil
+}
+
+// GenerateSelfSignedCert 为给定的主机名和IP生成一个临时的自签名证书，默认为localhost、127.0.0.1和::1。
+// GenerateSelfSignedCert generates an ephemeral self-signed certificate for the given host names and IPs, localhost, 127.0.0.1 and ::1 by default.
+// 它只用于开发。 It is meant for development only.
+func GenerateSelfSignedCert(hosts ...string) (tls.Certificate, error) {
+	if len(hosts) == 0 {
+		hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"gin-web development"}, CommonName: hosts[0]},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// RunTLSConfig 使用给定的tls.Config监听并提供HTTPS服务，直到调用Shutdown。 RunTLSConfig listens and serves HTTPS with the given tls.Config until Shutdown is called.
+func (engine *Engine) RunTLSConfig(addr string, config *tls.Config) (err error) {
+	DebugPrint("监听并提供HTTPS服务 Listening and serving HTTPS on %s\n", addr)
+	defer func() { debugPrintError(err) }()
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return
+	}
+	err = engine.serve(context.Background(), listener, serveTLS, ServerConfig{
+		Configure: func(srv *http.Server) { srv.TLSConfig = config },
+	})
+	return
+}
+
+// RunTLSReload 与RunTLS类似，但是当证书文件在磁盘上更改时会重新加载它们， RunTLSReload is like RunTLS but reloads the certificate files when they
+// 并且通过SNI在多个证书对之间进行选择。 change on disk and selects between several certificate pairs by SNI.
+func (engine *Engine) RunTLSReload(addr string, pairs ...CertPair) error {
+	reloader, err := NewCertReloader(CertReloadInterval, pairs...)
+	if err != nil {
+		debugPrintError(err)
+		return err
+	}
+	defer reloader.Close()
+	return engine.RunTLSConfig(addr, reloader.TLSConfig())
+}
+
+// RunSelfSigned 使用为hosts生成的临时自签名证书监听并提供HTTPS服务，仅用于开发。 RunSelfSigned listens and serves HTTPS with an ephemeral
+// self-signed certificate generated for hosts, for development only.
+func (engine *Engine) RunSelfSigned(addr string, hosts ...string) error {
+	cert, err := GenerateSelfSignedCert(hosts...)
+	if err != nil {
+		debugPrintError(err)
+		return err
+	}
+	DebugPrint("[警告]使用自签名证书 [WARNING] Using a self-signed certificate for %v, do not use it in production", cert.Leaf.DNSNames)
+	return engine.RunTLSConfig(addr, &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	})
+}
+
+// serveTLS 使用srv.TLSConfig在监听器上提供HTTPS服务 serveTLS serves HTTPS on the listener with srv.TLSConfig.
+func serveTLS(srv *http.Server, listener net.Listener) error {
+	return srv.ServeTLS(listener, "", "")
+}
@@ -0,0 +1,155 @@
+package gin_web
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertPair 为hosts生成证书并写入pair的文件 writeCertPair generates a certificate for hosts and writes it to the files of pair.
+func writeCertPair(t *testing.T, pair CertPair, hosts ...string) *x509.Certificate {
+	t.Helper()
+	cert, err := GenerateSelfSignedCert(hosts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pair.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pair.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert.Leaf
+}
+
+// handshake 与config握手并返回服务器提供的证书 handshake shakes hands with config and returns the certificate the server presented.
+func handshake(t *testing.T, config *tls.Config, client *tls.Config) (*x509.Certificate, error) {
+	t.Helper()
+	listener := listen(t)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if conn, err := listener.Accept(); err == nil {
+			tls.Server(conn, config).Handshake()
+			conn.Close()
+		}
+	}()
+	defer func() {
+		listener.Close()
+		<-done
+	}()
+	conn, err := tls.Dial("tcp", listener.Addr().String(), client)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	a := CertPair{filepath.Join(dir, "a.crt"), filepath.Join(dir, "a.key")}
+	b := CertPair{filepath.Join(dir, "b.crt"), filepath.Join(dir, "b.key")}
+	oldA := writeCertPair(t, a, "a.example.com")
+	certB := writeCertPair(t, b, "b.example.com", "*.b.example.org")
+
+	reloader, err := NewCertReloader(10*time.Millisecond, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloader.Close()
+	config := reloader.TLSConfig()
+	served := func(serverName string) *x509.Certificate {
+		t.Helper()
+		cert, err := handshake(t, config, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("handshake for %q: %v", serverName, err)
+		}
+		return cert
+	}
+
+	for _, tt := range []struct {
+		serverName string
+		want       *x509.Certificate
+	}{
+		{"a.example.com", oldA},
+		{"b.example.com", certB},
+		{"www.b.example.org", certB},
+		{"unknown.example.com", oldA},
+		{"", oldA},
+	} {
+		if got := served(tt.serverName); !got.Equal(tt.want) {
+			t.Errorf("SNI %q served %v, want %v", tt.serverName, got.DNSNames, tt.want.DNSNames)
+		}
+	}
+
+	//重写磁盘上的证书对，下一次握手提供新证书 // rewrite the pair on disk, the next handshake serves the new certificate
+	newA := writeCertPair(t, a, "a.example.com")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(a.CertFile, future, future)
+	deadline := time.Now().Add(2 * time.Second)
+	for !served("a.example.com").Equal(newA) {
+		if time.Now().After(deadline) {
+			t.Fatal("the rewritten certificate was not served")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := served("b.example.com"); !got.Equal(certB) {
+		t.Error("reloading a changed the certificate of b")
+	}
+
+	//写了一半的文件保留旧证书 // a half written file keeps the old certificate
+	os.WriteFile(a.CertFile, []byte("garbage"), 0600)
+	future = future.Add(time.Minute)
+	os.Chtimes(a.CertFile, future, future)
+	time.Sleep(50 * time.Millisecond)
+	if got := served("a.example.com"); !got.Equal(newA) {
+		t.Error("an invalid certificate file replaced the loaded certificate")
+	}
+
+	if _, err := NewCertReloader(0); err == nil {
+		t.Error("NewCertReloader accepted no pairs")
+	}
+	if _, err := NewCertReloader(0, CertPair{filepath.Join(dir, "missing.crt"), a.KeyFile}); err == nil {
+		t.Error("NewCertReloader accepted a missing file")
+	}
+}
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	tests := []struct {
+		hosts  []string
+		verify []string
+		reject string
+	}{
+		{nil, []string{"localhost", "127.0.0.1", "::1"}, "example.com"},
+		{[]string{"dev.example.com", "10.1.2.3"}, []string{"dev.example.com", "10.1.2.3"}, "localhost"},
+	}
+	for _, tt := range tests {
+		cert, err := GenerateSelfSignedCert(tt.hosts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cert.Leaf.IsCA || cert.Leaf.KeyUsage&x509.KeyUsageCertSign != 0 {
+			t.Errorf("%v: the self-signed leaf can sign certificates", tt.hosts)
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(cert.Leaf)
+		config := &tls.Config{Certificates: []tls.Certificate{cert}}
+		for _, host := range tt.verify {
+			if _, err := handshake(t, config, &tls.Config{ServerName: host, RootCAs: roots}); err != nil {
+				t.Errorf("%v: verifying %q: %v", tt.hosts, host, err)
+			}
+		}
+		if _, err := handshake(t, config, &tls.Config{ServerName: tt.reject, RootCAs: roots}); err == nil {
+			t.Errorf("%v: %q verified", tt.hosts, tt.reject)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package gin_web
 
 import (
 	"net/url"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -16,6 +17,48 @@ type Param struct {
 // 切片是有序的，第一个URL参数也是第一个切片值
 // 因此，通过索引读取值是安全的
 type Params []Param
+
+// Get 返回与给定名称匹配的第一个Param的值。 Get returns the value of the first Param which key matches the given name.
+// 如果找不到匹配的Param，则返回一个空字符串和false。 If no matching Param is found, an empty string and false are returned.
+func (ps Params) Get(name string) (string, bool) {
+	for _, entry := range ps {
+		if entry.Key == name {
+			return entry.Value, true
+		}
+	}
+	return "", false
+}
+
+// ByName 返回与给定名称匹配的第一个Param的值。 ByName returns the value of the first Param which key matches the given name.
+// 如果找不到匹配的Param，则返回一个空字符串。 If no matching Param is found, an empty string is returned.
+func (ps Params) ByName(name string) (va string) {
+	va, _ = ps.Get(name)
+	return
+}
+
+// Int 以int形式返回参数值，用于 :name<int> 约束的路由。 Int returns the param value as an int, intended for routes constrained with :name<int>.
+// 对于已匹配的路由它不会失败；如果参数缺失或无法解析，则返回0。 It never fails on a matched route; 0 is returned if the param is missing or can't be parsed.
+func (ps Params) Int(name string) int {
+	return int(ps.Int64(name))
+}
+
+// Int64 以int64形式返回参数值，如果无法解析则返回0。 Int64 returns the param value as an int64, or 0 if it can't be parsed.
+func (ps Params) Int64(name string) int64 {
+	v, _ := strconv.ParseInt(ps.ByName(name), 10, 64)
+	return v
+}
+
+// Uint 以uint形式返回参数值，用于 :name<uint> 约束的路由。 Uint returns the param value as an uint, intended for routes constrained with :name<uint>.
+func (ps Params) Uint(name string) uint {
+	return uint(ps.Uint64(name))
+}
+
+// Uint64 以uint64形式返回参数值，如果无法解析则返回0。 Uint64 returns the param value as an uint64, or 0 if it can't be parsed.
+func (ps Params) Uint64(name string) uint64 {
+	v, _ := strconv.ParseUint(ps.ByName(name), 10, 64)
+	return v
+}
+
 type nodeType uint8
 
 const (
@@ -35,6 +78,8 @@ type node struct {
 	maxParams uint8
 	wildChild bool
 	fullPath  string
+	// constraint 是参数节点的可选约束，例如 :id<int>  constraint is the optional constraint of a param node, e.g. :id<int>
+	constraint *paramConstraint
 }
 type methodTree struct {
 	method string
@@ -42,8 +87,7 @@ type methodTree struct {
 }
 type methodTrees []methodTree
 
-// Get返回与给定名称匹配的第一个Param的值。 // Get returns the value of the first Param which key matches the given name.
-//如果找不到匹配的Param，则返回一个空字符串。 // If no matching Param is found, an empty string is returned.
+// get返回给定方法的树根 // get returns the root of the tree for the given method.
 func (trees methodTrees) get(method string) *node {
 	for _, tree := range trees {
 		if tree.method == method {
@@ -53,11 +97,33 @@ func (trees methodTrees) get(method string) *node {
 	return nil
 }
 
+// set 替换给定方法的树根，root为nil时删除该树。 set replaces the root of the tree for the given method, removing the tree if root is nil.
+func (trees *methodTrees) set(method string, root *node) {
+	for i, tree := range *trees {
+		if tree.method != method {
+			continue
+		}
+		if root == nil {
+			*trees = append((*trees)[:i:i], (*trees)[i+1:]...)
+		} else {
+			(*trees)[i].root = root
+		}
+		return
+	}
+	if root != nil {
+		*trees = append(*trees, methodTree{method: method, root: root})
+	}
+}
+
 func counParams(path string) uint8 {
 	var n uint
 	for i := 0; i < len(path); i++ {
-		if path[i] == ':' || path[i] == '*' {
+		switch path[i] {
+		case ':', '*':
 			n++
+		case '<':
+			//约束中的字符不计入参数 // chars inside a constraint are not params
+			i = skipConstraint(path, i)
 		}
 	}
 	if n > 255 {
@@ -77,12 +143,17 @@ func findWildcard(path string) (wildcard string, i int, valid bool) {
 		}
 		//查找结尾并检查无效字符 // Find end and check for invalid characters
 		valid = true
-		for end, c := range []byte(path[start+1:]) {
-			switch c {
+		for end := start + 1; end < len(path); end++ {
+			switch path[end] {
 			case '/':
-				return path[start : start+1+end], start, valid
+				return path[start:end], start, valid
 			case ':', '*':
 				valid = false
+			case '<':
+				//跳过参数约束，例如 :name<[a-z]+>  // skip a param constraint such as :name<[a-z]+>
+				if c == ':' {
+					end = skipConstraint(path, end)
+				}
 			}
 		}
 		return path[start:], start, valid
@@ -121,11 +192,13 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 			}
 
 			n.wildChild = true
+			_, constraint := parseParamWildcard(wildcard, fullPath)
 			child := &node{
-				nType:     param,
-				path:      wildcard,
-				maxParams: numParams,
-				fullPath:  fullPath,
+				nType:      param,
+				path:       wildcard,
+				maxParams:  numParams,
+				fullPath:   fullPath,
+				constraint: constraint,
 			}
 			n.children = []*node{child}
 			n = child
@@ -174,6 +247,15 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 			maxParams: 1,
 			fullPath:  fullPath,
 		}
+		//更新父路径的maxParams	// update maxParams of the parent node
+		if n.maxParams < 1 {
+			n.maxParams = 1
+		}
+		n.children = []*node{child}
+		n.indices = string('/')
+		n = child
+		n.priority++
+
 		//第二个节点：保存变量的节点	// second node: node holding the variable
 		child = &node{
 			path:      path[i:],
@@ -278,10 +360,10 @@ walk:
 			n.children = []*node{&child}
 			//[] byte用于正确的Unicode字符转换，请参见＃65 // []byte for proper unicode char conversion , see #65
 			n.indices = string([]byte{n.path[i]})
-			n.path = path[i:]
+			n.path = path[:i]
 			n.handlers = nil
 			n.wildChild = false
-			n.fullPath = n.fullPath[:parentFullPathIndex+1]
+			n.fullPath = fullPath[:parentFullPathIndex+i]
 		}
 
 		//使新节点成为该节点的子节点	// make new node a child of this node
@@ -290,23 +372,32 @@ walk:
 
 			if n.wildChild {
 				parentFullPathIndex += len(n.path)
-				n = n.children[0]
-				n.priority++
-
-				//更新子节点的maxParams	// update maxParams of the child node
-				if numParams > n.maxParams {
-					n.maxParams = numParams
-				}
-				numParams--
-
-				//检查通配符是否匹配	// check if the wildcard matches
-				if len(path) >= len(n.path) && n.path == path[:len(n.path)] {
-					//检查记录器通配符，例如 ：name和：names	// check ofr loger wildcard e.g. :name and :names
-					if len(n.path) >= len(path) || path[len(n.path)] == '/' {
+				parent := n
+
+				//检查通配符是否匹配	// check if one of the wildcards matches
+				for _, child := range parent.children {
+					if len(path) >= len(child.path) && child.path == path[:len(child.path)] &&
+						//检查记录器通配符，例如 ：name和：names	// check ofr loger wildcard e.g. :name and :names
+						(len(child.path) >= len(path) || path[len(child.path)] == '/') {
+						n = child
+						n.priority++
+
+						//更新子节点的maxParams	// update maxParams of the child node
+						if numParams > n.maxParams {
+							n.maxParams = numParams
+						}
+						numParams--
 						continue walk
 					}
 				}
 
+				//带约束的参数可以与其他参数并列 // a constrained param may sit next to other params
+				if path[0] == ':' && parent.canAddParamSibling(path, fullPath) {
+					parent.insertParamSibling(numParams, path, fullPath, handlers)
+					return
+				}
+
+				n = parent.children[0]
 				pathSeg := path
 				if n.nType != catchAll {
 					pathSeg = strings.SplitN(path, "/", 2)[0]
@@ -361,6 +452,55 @@ walk:
 	}
 }
 
+// canAddParamSibling 报告是否可以在现有参数旁边插入以path开头的参数。 canAddParamSibling reports whether the param path starts with
+// can be inserted next to the existing params of n.
+// 所有参数中最多只能有一个不带约束。 At most one of the params may be unconstrained.
+func (n *node) canAddParamSibling(path, fullPath string) bool {
+	if n.children[0].nType != param {
+		return false
+	}
+	wildcard, _, valid := findWildcard(path)
+	if !valid {
+		return false
+	}
+	if _, constraint := parseParamWildcard(wildcard, fullPath); constraint != nil {
+		return true
+	}
+	for _, child := range n.children {
+		if child.constraint == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// insertParamSibling 在n的现有参数旁边插入一个新的参数子树。 insertParamSibling inserts a new param subtree next to the existing params of n.
+// 带约束的参数按注册顺序尝试，不带约束的参数总是最后尝试。 Constrained params are tried in registration order, the unconstrained one is always tried last.
+func (n *node) insertParamSibling(numParams uint8, path, fullPath string, handlers HandlersChain) {
+	holder := &node{}
+	holder.insertChild(numParams, path, fullPath, handlers)
+	child := holder.children[0]
+
+	pos := len(n.children)
+	if child.constraint != nil && n.children[pos-1].constraint == nil {
+		pos--
+	}
+	n.children = append(n.children, nil)
+	copy(n.children[pos+1:], n.children[pos:])
+	n.children[pos] = child
+}
+
+// growParams 确保ps可以再容纳n个参数，并保留已保存的参数，例如主机通配符。 growParams makes sure ps has room for n more params,
+// keeping the params already saved, e.g. host wildcards.
+func growParams(ps Params, n uint8) Params {
+	if cap(ps)-len(ps) >= int(n) {
+		return ps
+	}
+	grown := make(Params, len(ps), len(ps)+int(n))
+	copy(grown, ps)
+	return grown
+}
+
 // nodeValue保存（* Node）.getValue方法的返回值 // nodeValue holds return values of (*Node).getValue method
 type nodeValue struct {
 	handlers HandlersChain
@@ -425,6 +565,9 @@ walk: //外循环使树走 // outer loop for walking the tree
 				return
 			}
 			//处理通配符子级	// handle wildcard child
+			if len(n.children) > 1 {
+				return n.getParamSiblingValue(path, value.params, unescape)
+			}
 			n = n.children[0]
 			switch n.nType {
 			case param:
@@ -434,13 +577,16 @@ walk: //外循环使树走 // outer loop for walking the tree
 					end++
 				}
 
-				//保存参数值	// save param value
-				if acp(value.params) < int(n.maxParams) {
-					value.params = make(Params, 0, n.maxParams)
+				//不满足约束的值不匹配此路由 // a value failing the constraint doesn't match this route
+				if n.constraint != nil && !n.constraint.match(path[:end]) {
+					return
 				}
+
+				//保存参数值	// save param value
+				value.params = growParams(value.params, n.maxParams)
 				i := len(value.params)
 				value.params = value.params[:i+1] //在预分配的容量内扩展切片 // expand slice within preallocated capacity
-				value.params[i].Key = n.path[1:]
+				value.params[i].Key = paramKey(n.path)
 				val := path[:end]
 				if unescape {
 					var err error
@@ -476,9 +622,7 @@ walk: //外循环使树走 // outer loop for walking the tree
 				return
 			case catchAll:
 				// save param value
-				if cap(value.params) < int(n.maxParams) {
-					value.params = make(Params, 0, n.maxParams)
-				}
+				value.params = growParams(value.params, n.maxParams)
 				i := len(value.params)
 				value.params = value.params[:i+1] //在预分配的容量内扩展切片 // expand slice within preallocated capacity
 				value.params[i].Key = n.path[2:]
@@ -506,6 +650,21 @@ walk: //外循环使树走 // outer loop for walking the tree
 	}
 }
 
+// getParamSiblingValue 依次尝试n的每个参数子节点，直到其中一个找到句柄。 getParamSiblingValue tries every param child of n in order
+// until one of them leads to a handle, so a value failing one constraint can still match a sibling route.
+func (n *node) getParamSiblingValue(path string, po Params, unescape bool) (value nodeValue) {
+	tsr := false
+	for _, child := range n.children {
+		probe := node{wildChild: true, children: []*node{child}}
+		if value = probe.getValue(path, po, unescape); value.handlers != nil {
+			return
+		}
+		tsr = tsr || value.tsr
+	}
+	value.tsr = tsr
+	return
+}
+
 // findCaseInsensitivePath对给定路径进行不区分大小写的查找，并尝试查找处理程序。 // findCaseInsensitivePath makes a case-insensitive lookup of the given path and tries to find a handler.
 //也可以选择修复斜杠。 // It can optionally also fix trailing slashes.
 //返回经过大小写校正的路径和一个布尔值，指示是否查找 // It returns the case-corrected path and a bool indicating whether the lookup
@@ -564,6 +723,16 @@ func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (ciPa
 			return
 		}
 
+		//参数兄弟节点依次尝试，与getValue相同 // param siblings are tried in order, like getValue does
+		if len(n.children) > 1 {
+			for _, child := range n.children {
+				probe := node{wildChild: true, children: []*node{child}}
+				if out, found := probe.findCaseInsensitivePath(path, fixTrailingSlash); found {
+					return append(ciPath, out...), true
+				}
+			}
+			return
+		}
 		n = n.children[0]
 		switch n.nType {
 		case param:
@@ -572,14 +741,26 @@ func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (ciPa
 			for end < len(path) && path[end] != '/' {
 				end++
 			}
+			//不满足约束的值不匹配此路由 // a value failing the constraint doesn't match this route
+			if n.constraint != nil && !n.constraint.match(path[:end]) {
+				return
+			}
 			//将参数值添加到不区分大小写的路径	// add param value to case insensitive path
 			ciPath = append(ciPath, path[:end]...)
 
 			//我们需要更深入！ //we need to go deeper!
-			if fixTrailingSlash && len(path) == end+1 {
-				return ciPath, true
+			if end < len(path) {
+				if len(n.children) > 0 {
+					path = path[end:]
+					n = n.children[0]
+					continue
+				}
+				//...但是我们不能 // ... but we can't
+				if fixTrailingSlash && len(path) == end+1 {
+					return ciPath, true
+				}
+				return
 			}
-			return
 
 			if n.handlers != nil {
 				return ciPath, true
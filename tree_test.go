@@ -0,0 +1,219 @@
+package gin_web
+
+import (
+	"strings"
+	"testing"
+)
+
+func fakeHandler(string) HandlersChain {
+	return HandlersChain{func(*Context) {}}
+}
+
+type testRequests []struct {
+	path       string
+	nilHandler bool
+	route      string
+	ps         Params
+}
+
+func checkRequests(t *testing.T, tree *node, requests testRequests) {
+	t.Helper()
+	for _, request := range requests {
+		value := tree.getValue(request.path, nil, false)
+		if value.handlers == nil {
+			if !request.nilHandler {
+				t.Errorf("handle mismatch for route '%s': Expected non-nil handle", request.path)
+			}
+			continue
+		}
+		if request.nilHandler {
+			t.Errorf("handle mismatch for route '%s': Expected nil handle, got '%s'", request.path, value.fullPath)
+			continue
+		}
+		if value.fullPath != request.route {
+			t.Errorf("route mismatch for '%s': got '%s', want '%s'", request.path, value.fullPath, request.route)
+		}
+		if len(value.params) != len(request.ps) {
+			t.Errorf("Params mismatch for route '%s': got %v, want %v", request.path, value.params, request.ps)
+			continue
+		}
+		for i := range request.ps {
+			if value.params[i] != request.ps[i] {
+				t.Errorf("Params mismatch for route '%s': got %v, want %v", request.path, value.params, request.ps)
+				break
+			}
+		}
+	}
+}
+
+func catchPanic(testFunc func()) (recv interface{}) {
+	defer func() {
+		recv = recover()
+	}()
+	testFunc()
+	return
+}
+
+func TestTreeParamConstraints(t *testing.T) {
+	tree := &node{}
+	routes := [...]string{
+		"/users/:id<int>",
+		"/users/:name<alpha>/posts",
+		"/files/:uuid<uuid>",
+		"/codes/:code<[A-Z]{3}>",
+		"/versions/:v<uint>",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/users/42", false, "/users/:id<int>", Params{{"id", "42"}}},
+		{"/users/-7", false, "/users/:id<int>", Params{{"id", "-7"}}},
+		{"/users/abc", true, "", nil},
+		{"/users/abc/posts", false, "/users/:name<alpha>/posts", Params{{"name", "abc"}}},
+		{"/users/42/posts", true, "", nil},
+		{"/files/123e4567-e89b-12d3-a456-426614174000", false, "/files/:uuid<uuid>", Params{{"uuid", "123e4567-e89b-12d3-a456-426614174000"}}},
+		{"/files/123e4567", true, "", nil},
+		{"/codes/ABC", false, "/codes/:code<[A-Z]{3}>", Params{{"code", "ABC"}}},
+		{"/codes/ABCD", true, "", nil},
+		{"/codes/abc", true, "", nil},
+		{"/versions/3", false, "/versions/:v<uint>", Params{{"v", "3"}}},
+		{"/versions/-3", true, "", nil},
+	})
+}
+
+func TestTreeParamSiblings(t *testing.T) {
+	tree := &node{}
+	routes := [...]string{
+		"/items/:id<int>",
+		"/items/:slug",
+		"/items/:uuid<uuid>",
+		"/items/:id<int>/detail",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/items/1", false, "/items/:id<int>", Params{{"id", "1"}}},
+		{"/items/1/detail", false, "/items/:id<int>/detail", Params{{"id", "1"}}},
+		{"/items/123e4567-e89b-12d3-a456-426614174000", false, "/items/:uuid<uuid>", Params{{"uuid", "123e4567-e89b-12d3-a456-426614174000"}}},
+		{"/items/hello", false, "/items/:slug", Params{{"slug", "hello"}}},
+		{"/items/hello/detail", true, "", nil},
+	})
+}
+
+func TestTreeFindCaseInsensitivePathConstraints(t *testing.T) {
+	tree := &node{}
+	routes := [...]string{
+		"/users/:id<int>",
+		"/users/:name<alpha>/posts",
+		"/codes/:code<[A-Z]{3}>",
+		"/items/:id<int>/detail",
+		"/items/:slug/info",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	tests := []struct {
+		in    string
+		out   string
+		found bool
+	}{
+		{"/USERS/42", "/users/42", true},
+		{"/USERS/abc", "", false},
+		{"/USERS/abc/POSTS", "/users/abc/posts", true},
+		{"/USERS/42/POSTS", "", false},
+		{"/CODES/ABC", "/codes/ABC", true},
+		{"/CODES/abc", "", false},
+		{"/ITEMS/7/DETAIL", "/items/7/detail", true},
+		{"/ITEMS/7/INFO", "/items/7/info", true},
+		{"/ITEMS/hello/INFO", "/items/hello/info", true},
+		{"/ITEMS/hello/DETAIL", "", false},
+		{"/users/42/", "/users/42", true},
+	}
+	for _, tt := range tests {
+		out, found := tree.findCaseInsensitivePath(tt.in, true)
+		if found != tt.found || found && string(out) != tt.out {
+			t.Errorf("findCaseInsensitivePath(%q) = %q, %v, want %q, %v", tt.in, out, found, tt.out, tt.found)
+		}
+	}
+}
+
+func TestTreeParamConstraintConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []string
+		panics string
+	}{
+		{"two unconstrained", []string{"/a/:x", "/a/:y"}, "conflicts"},
+		{"unclosed constraint", []string{"/a/:x<int"}, "must end with '>'"},
+		{"empty constraint", []string{"/a/:x<>"}, "can not be empty"},
+		{"unnamed constraint", []string{"/a/:<int>"}, "non-empty name"},
+		{"invalid regexp", []string{"/a/:x<[a-z>"}, "invalid param constraint"},
+		{"constrained next to catch-all", []string{"/a/*all", "/a/:x<int>"}, "conflicts"},
+		{"constrained siblings", []string{"/a/:x<int>", "/a/:y<alpha>", "/a/:z"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := &node{}
+			recv := catchPanic(func() {
+				for _, route := range tt.routes {
+					tree.addRoute(route, fakeHandler(route))
+				}
+			})
+			if tt.panics == "" {
+				if recv != nil {
+					t.Fatalf("unexpected panic: %v", recv)
+				}
+				return
+			}
+			if recv == nil {
+				t.Fatalf("expected a panic containing %q", tt.panics)
+			}
+			if msg, _ := recv.(string); !strings.Contains(msg, tt.panics) {
+				t.Fatalf("panic %q does not contain %q", recv, tt.panics)
+			}
+		})
+	}
+}
+
+func TestRegisterParamConstraint(t *testing.T) {
+	RegisterParamConstraint("even", func(v string) bool {
+		return isUintParam(v) && (v[len(v)-1]-'0')%2 == 0
+	})
+	defer delete(paramConstraints, "even")
+
+	tree := &node{}
+	tree.addRoute("/n/:n<even>", fakeHandler("/n/:n<even>"))
+	checkRequests(t, tree, testRequests{
+		{"/n/12", false, "/n/:n<even>", Params{{"n", "12"}}},
+		{"/n/13", true, "", nil},
+	})
+
+	if recv := catchPanic(func() { RegisterParamConstraint("", isIntParam) }); recv == nil {
+		t.Error("expected a panic for an empty constraint name")
+	}
+}
+
+func TestParamsTypedAccessors(t *testing.T) {
+	ps := Params{{"id", "-12"}, {"n", "7"}, {"bad", "x"}}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"Int", ps.Int("id"), -12},
+		{"Int64", ps.Int64("id"), int64(-12)},
+		{"Uint", ps.Uint("n"), uint(7)},
+		{"Uint64 invalid", ps.Uint64("bad"), uint64(0)},
+		{"Int missing", ps.Int("missing"), 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
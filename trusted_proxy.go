@@ -0,0 +1,110 @@
+package gin_web
+
+import (
+	"net"
+	"strings"
+)
+
+// 可以用作Engine.TrustedPlatform的可信平台头 Trusted platform headers which can be used as Engine.TrustedPlatform.
+const (
+	// PlatformGoogleAppEngine 是Google App Engine设置的客户端IP头 PlatformGoogleAppEngine is the client IP header set by Google App Engine.
+	PlatformGoogleAppEngine = "X-Appengine-Remote-Addr"
+	// PlatformCloudflare 是Cloudflare设置的客户端IP头 PlatformCloudflare is the client IP header set by Cloudflare.
+	PlatformCloudflare = "CF-Connecting-IP"
+	// PlatformFlyIO 是Fly.io设置的客户端IP头 PlatformFlyIO is the client IP header set by Fly.io.
+	PlatformFlyIO = "Fly-Client-IP"
+)
+
+// SetTrustedProxies 设置其RemoteIPHeaders被ClientIP信任的代理的IP或CIDR。 SetTrustedProxies sets the IPs or CIDRs of the proxies
+// whose RemoteIPHeaders are trusted by ClientIP.
+// 默认情况下不信任任何代理，nil会恢复此行为。无效的输入返回错误且不改变当前列表。 No proxy is trusted by default, nil restores that.
+// An invalid input returns an error and leaves the current list unchanged.
+// 它必须在服务开始之前调用。 It must be called before serving.
+func (engine *Engine) SetTrustedProxies(trustedProxies []string) error {
+	cidrs, err := parseTrustedSources(trustedProxies)
+	if err != nil {
+		return err
+	}
+	engine.trustedCIDRs = cidrs
+	return nil
+}
+
+// isTrustedProxy 判断ip是否是可信代理 isTrustedProxy reports whether ip is a trusted proxy.
+func (engine *Engine) isTrustedProxy(ip net.IP) bool {
+	return containsIP(engine.trustedCIDRs, ip)
+}
+
+// clientIPFromHeader 从右到左遍历头中的跳，返回第一个不可信的跳。 clientIPFromHeader walks the hops of the header right-to-left and returns
+// the first untrusted one.
+// 如果头为空或在不可信的跳之前遇到无效的跳，则ok为false。 ok is false if the header is empty or an invalid hop is met before an untrusted one.
+func (engine *Engine) clientIPFromHeader(name, value string) (clientIP string, ok bool) {
+	if value == "" {
+		return "", false
+	}
+	var hops []string
+	if strings.EqualFold(name, "Forwarded") {
+		hops = forwardedFor(value)
+	} else {
+		hops = strings.Split(value, ",")
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return "", false
+		}
+		if i == 0 || !engine.isTrustedProxy(ip) {
+			return ip.String(), true
+		}
+	}
+	return "", false
+}
+
+// forwardedFor 返回RFC 7239 Forwarded头中每个元素的for=节点，去掉引号、方括号和端口。 forwardedFor returns the for= node of every element
+// of a RFC 7239 Forwarded header, without quotes, brackets and ports.
+// 没有for=的元素返回空节点，以便遍历在此停止。 An element without for= yields an empty node so that the walk stops there.
+//
+//	Forwarded: for=192.0.2.60;proto=http, for="[2001:db8::1]:4711"
+func forwardedFor(value string) []string {
+	var nodes []string
+	for _, element := range splitQuoted(value, ',') {
+		node := ""
+		for _, pair := range splitQuoted(element, ';') {
+			i := strings.IndexByte(pair, '=')
+			if i < 0 || !strings.EqualFold(strings.TrimSpace(pair[:i]), "for") {
+				continue
+			}
+			node = strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+			if strings.HasPrefix(node, "[") {
+				if end := strings.IndexByte(node, ']'); end > 0 {
+					node = node[1:end]
+				}
+			} else if host, _, err := net.SplitHostPort(node); err == nil {
+				node = host
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// splitQuoted 按sep分割s，忽略引号内的分隔符 splitQuoted splits s by sep, ignoring the separators inside quotes.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if quoted {
+				i++
+			}
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
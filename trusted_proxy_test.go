@@ -0,0 +1,144 @@
+package gin_web
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestForwardedFor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"for=192.0.2.60;proto=http;by=203.0.113.43", []string{"192.0.2.60"}},
+		{`for="[2001:db8::1]:4711"`, []string{"2001:db8::1"}},
+		{`For="192.0.2.43:47011", for=198.51.100.17`, []string{"192.0.2.43", "198.51.100.17"}},
+		{"proto=https, for=10.0.0.1", []string{"", "10.0.0.1"}},
+		{`for=unknown, for="_hidden"`, []string{"unknown", "_hidden"}},
+		{`for="1.1.1.1, 2.2.2.2";proto=http`, []string{"1.1.1.1, 2.2.2.2"}},
+		{`for="a\";b", for=3.3.3.3`, []string{`a\";b`, "3.3.3.3"}},
+	}
+	for _, tt := range tests {
+		if got := forwardedFor(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("forwardedFor(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestClientIPSpoofing(t *testing.T) {
+	tests := []struct {
+		name     string
+		trusted  []string
+		headers  []string
+		remote   string
+		request  map[string]string
+		clientIP string
+	}{
+		{"untrusted remote ignores headers", nil, nil, "10.0.0.5:1234",
+			map[string]string{"X-Forwarded-For": "1.1.1.1"}, "10.0.0.5"},
+		{"walks right to left", []string{"10.0.0.0/8"}, nil, "10.0.0.5:1234",
+			map[string]string{"X-Forwarded-For": "6.6.6.6, 2.2.2.2, 10.0.0.9"}, "2.2.2.2"},
+		{"every hop trusted", []string{"10.0.0.0/8"}, nil, "10.0.0.5:1234",
+			map[string]string{"X-Forwarded-For": "10.0.0.1"}, "10.0.0.1"},
+		{"invalid hop stops the walk", []string{"10.0.0.0/8"}, nil, "10.0.0.5:1234",
+			map[string]string{"X-Forwarded-For": "6.6.6.6, bogus, 10.0.0.9"}, "10.0.0.5"},
+		{"invalid header does not fall through", []string{"10.0.0.0/8"}, nil, "10.0.0.5:1234",
+			map[string]string{"X-Forwarded-For": "bogus", "X-Real-IP": "6.6.6.6"}, "10.0.0.5"},
+		{"next header used when absent", []string{"10.0.0.0/8"}, nil, "10.0.0.5:1234",
+			map[string]string{"X-Real-IP": "3.3.3.3"}, "3.3.3.3"},
+		{"first present header wins", []string{"10.0.0.0/8"}, nil, "10.0.0.5:1234",
+			map[string]string{"X-Forwarded-For": "2.2.2.2", "X-Real-IP": "6.6.6.6"}, "2.2.2.2"},
+		{"forwarded ignored by default", []string{"10.0.0.0/8"}, nil, "10.0.0.5:1234",
+			map[string]string{"Forwarded": "for=6.6.6.6"}, "10.0.0.5"},
+		{"forwarded opt-in", []string{"10.0.0.0/8"}, []string{"Forwarded"}, "10.0.0.5:1234",
+			map[string]string{"Forwarded": `for=6.6.6.6;proto=http, for="[2001:db8::1]:4711", for=10.1.1.1:80`}, "2001:db8::1"},
+		{"forwarded obfuscated hop", []string{"10.0.0.0/8"}, []string{"Forwarded", "X-Forwarded-For"}, "10.0.0.5:1234",
+			map[string]string{"Forwarded": "for=6.6.6.6, for=_hidden", "X-Forwarded-For": "6.6.6.6"}, "10.0.0.5"},
+		{"forwarded element without for", []string{"10.0.0.0/8"}, []string{"Forwarded"}, "10.0.0.5:1234",
+			map[string]string{"Forwarded": "for=6.6.6.6, proto=https"}, "10.0.0.5"},
+		{"ipv6 proxy", []string{"::1"}, nil, "[::1]:80",
+			map[string]string{"X-Forwarded-For": "2001:db8::2"}, "2001:db8::2"},
+	}
+	for _, tt := range tests {
+		router := New()
+		if err := router.SetTrustedProxies(tt.trusted); err != nil {
+			t.Fatal(err)
+		}
+		if tt.headers != nil {
+			router.RemoteIPHeaders = tt.headers
+		}
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remote
+		for name, value := range tt.request {
+			req.Header.Set(name, value)
+		}
+		c := &Context{engine: router, Request: req}
+		if ip := c.ClientIP(); ip != tt.clientIP {
+			t.Errorf("%s: ClientIP() = %q, want %q", tt.name, ip, tt.clientIP)
+		}
+	}
+}
+
+func TestClientIPPlatform(t *testing.T) {
+	router := New()
+	router.TrustedPlatform = PlatformCloudflare
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	c := &Context{engine: router, Request: req}
+	if ip := c.ClientIP(); ip != "10.0.0.5" {
+		t.Errorf("ClientIP() without the platform header = %q", ip)
+	}
+	req.Header.Set(PlatformCloudflare, "3.3.3.3")
+	if ip := c.ClientIP(); ip != "3.3.3.3" {
+		t.Errorf("ClientIP() = %q, want the platform header", ip)
+	}
+	if err := router.SetTrustedProxies([]string{"10.0.0.0/8", "bogus"}); err == nil {
+		t.Error("SetTrustedProxies accepted an invalid IP")
+	}
+}
+
+func TestClientIPMultipleHeaderLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  []string
+		header   string
+		lines    []string
+		clientIP string
+	}{
+		{"x-forwarded-for lines are joined", nil, "X-Forwarded-For", []string{"6.6.6.6", "2.2.2.2, 10.0.0.9"}, "2.2.2.2"},
+		{"last line is the closest hop", nil, "X-Forwarded-For", []string{"2.2.2.2", "10.0.0.8", "10.0.0.9"}, "2.2.2.2"},
+		{"invalid hop in a later line", nil, "X-Forwarded-For", []string{"6.6.6.6", "bogus"}, "10.0.0.5"},
+		{"forwarded lines are joined", []string{"Forwarded"}, "Forwarded", []string{"for=6.6.6.6", "for=3.3.3.3;proto=https"}, "3.3.3.3"},
+		{"forwarded obfuscated later line", []string{"Forwarded"}, "Forwarded", []string{"for=6.6.6.6", "for=_hidden"}, "10.0.0.5"},
+	}
+	for _, tt := range tests {
+		router := New()
+		if err := router.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+			t.Fatal(err)
+		}
+		if tt.headers != nil {
+			router.RemoteIPHeaders = tt.headers
+		}
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.5:1234"
+		for _, line := range tt.lines {
+			req.Header.Add(tt.header, line)
+		}
+		c := &Context{engine: router, Request: req}
+		if ip := c.ClientIP(); ip != tt.clientIP {
+			t.Errorf("%s: ClientIP() = %q, want %q", tt.name, ip, tt.clientIP)
+		}
+	}
+
+	router := New()
+	router.TrustedPlatform = PlatformCloudflare
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	req.Header.Add(PlatformCloudflare, "3.3.3.3")
+	req.Header.Add(PlatformCloudflare, "6.6.6.6")
+	c := &Context{engine: router, Request: req}
+	if ip := c.ClientIP(); ip != "10.0.0.5" {
+		t.Errorf("ClientIP() with a repeated platform header = %q, want the remote address", ip)
+	}
+}
@@ -0,0 +1,322 @@
+package gin_web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// upgradeFdsEnv 是传给子进程的环境变量，保存继承的监听器数量 upgradeFdsEnv is the env marker passed to the child with the number of inherited listeners.
+// 监听器从fd 3开始，后面跟着就绪管道。 The listeners start at fd 3 and are followed by the ready pipe.
+const upgradeFdsEnv = "GIN_WEB_UPGRADE_FDS"
+
+// UpgradeTimeout 是等待子进程就绪的最长时间 UpgradeTimeout is the maximum time to wait for the child to be ready.
+var UpgradeTimeout = time.Minute
+
+// handoffDrainTimeout 是ReadHeaderTimeout被禁用时等待新连接发送第一个请求的时间 handoffDrainTimeout is how long the parent waits
+// for new connections to send their first request when ReadHeaderTimeout is disabled, the same grace net/http gives them on Shutdown.
+const handoffDrainTimeout = 5 * time.Second
+
+// upgrader 保存可升级服务器的监听器 upgrader holds the listeners of the upgradable server.
+type upgrader struct {
+	mu        sync.Mutex
+	listeners []net.Listener
+	handoffs  []*handoffListener
+	stop      context.CancelFunc
+	upgrading bool
+	// fresh 是已接受但尚未读取第一个请求的连接 fresh holds the accepted connections whose first request was not read yet
+	fresh map[net.Conn]struct{}
+}
+
+// RunUpgradable 在给定的TCP地址上为引擎服务，并支持零停机重启。 RunUpgradable serves the engine on the given TCP addresses with zero-downtime restarts.
+// 收到SIGHUP或调用Upgrade时，当前二进制文件会以继承的监听器重新执行， On SIGHUP or a call to Upgrade, the current binary is re-executed with the
+// 子进程就绪后，父进程优雅地关闭并返回nil。 inherited listeners, once the child is ready the parent shuts down gracefully and returns nil.
+// 在子进程中，同样的调用会使用继承的监听器而不是重新绑定。 In the child, the same call uses the inherited listeners instead of binding again.
+// SIGINT和SIGTERM会优雅地关闭服务器。 SIGINT and SIGTERM gracefully shut the server down.
+func (engine *Engine) RunUpgradable(addrs ...string) (err error) {
+	defer func() { debugPrintError(err) }()
+	if len(addrs) == 0 {
+		return errors.New("至少需要一个地址 at least one address is required")
+	}
+
+	listeners, ready, err := inheritedListeners()
+	if err != nil {
+		return err
+	}
+	if listeners == nil {
+		for _, addr := range addrs {
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				closeListeners(listeners)
+				return err
+			}
+			listeners = append(listeners, listener)
+		}
+	} else if len(listeners) != len(addrs) {
+		closeListeners(listeners)
+		return fmt.Errorf("继承了%d个监听器，但给出了%d个地址 inherited %d listeners but %d addresses were given", len(listeners), len(addrs), len(listeners), len(addrs))
+	} else {
+		DebugPrint("使用继承的监听器 Using %d inherited listener(s)", len(listeners))
+	}
+	if ready != nil {
+		var once sync.Once
+		engine.OnStart(func(context.Context) error {
+			once.Do(func() {
+				ready.Write([]byte{1})
+				ready.Close()
+			})
+			return nil
+		})
+	}
+
+	ctx, stop := signalContext(os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	u := &engine.upgrader
+	handoffs := make([]*handoffListener, len(listeners))
+	serving := make([]net.Listener, len(listeners))
+	config := ServerConfig{ConnState: u.trackConnState(engine.ServerConfig.ConnState)}
+	for i, listener := range listeners {
+		handoffs[i] = &handoffListener{Listener: listener, closed: make(chan struct{})}
+		serving[i] = ListenerWithConfig(handoffs[i], config)
+	}
+	u.mu.Lock()
+	u.listeners, u.handoffs, u.stop = listeners, handoffs, stop
+	u.fresh = make(map[net.Conn]struct{})
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		u.listeners, u.handoffs, u.stop, u.fresh = nil, nil, nil, nil
+		u.mu.Unlock()
+	}()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				if err := engine.Upgrade(); err != nil {
+					debugPrintError(err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return engine.ServeContext(ctx, serving...)
+}
+
+// Upgrade 以继承的监听器重新执行当前二进制文件，并等待子进程就绪， Upgrade re-executes the current binary with the inherited listeners and waits
+// 然后优雅地关闭RunUpgradable。如果子进程失败，父进程继续服务。 for the child to be ready, then gracefully shuts RunUpgradable down. If the child fails, the parent keeps serving.
+// 父进程先停止接受连接，并等待已接受的连接发送第一个请求，因为net/http在关闭期间会丢弃这些请求。
+// The parent first stops accepting and waits for the connections it already accepted to send their first request,
+// since net/http drops those requests once Shutdown started.
+func (engine *Engine) Upgrade() error {
+	u := &engine.upgrader
+	u.mu.Lock()
+	if u.listeners == nil {
+		u.mu.Unlock()
+		return errors.New("服务器不可升级，请使用RunUpgradable the server is not upgradable, use RunUpgradable")
+	}
+	if u.upgrading {
+		u.mu.Unlock()
+		return errors.New("升级已在进行中 an upgrade is already in progress")
+	}
+	u.upgrading = true
+	listeners, handoffs, stop := u.listeners, u.handoffs, u.stop
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		u.upgrading = false
+		u.mu.Unlock()
+	}()
+
+	if err := forkChild(listeners); err != nil {
+		return err
+	}
+	DebugPrint("子进程已就绪，正在关闭父进程 Child is ready, shutting the parent down")
+	for _, listener := range handoffs {
+		listener.handOff()
+	}
+	timeout := engine.ServerConfig.ReadHeaderTimeout
+	if timeout <= 0 {
+		timeout = handoffDrainTimeout
+	}
+	u.waitFresh(timeout)
+	stop()
+	return nil
+}
+
+// trackConnState 返回一个ConnState钩子，它记录尚未读取第一个请求的连接，然后调用next。 trackConnState returns a ConnState hook
+// recording the connections whose first request was not read yet, then calling next.
+func (u *upgrader) trackConnState(next func(net.Conn, http.ConnState)) func(net.Conn, http.ConnState) {
+	return func(conn net.Conn, state http.ConnState) {
+		u.mu.Lock()
+		if u.fresh != nil {
+			if state == http.StateNew {
+				u.fresh[conn] = struct{}{}
+			} else {
+				delete(u.fresh, conn)
+			}
+		}
+		u.mu.Unlock()
+		if next != nil {
+			next(conn, state)
+		}
+	}
+}
+
+// waitFresh 等待所有新连接读取第一个请求，最多等待timeout waitFresh waits up to timeout for every fresh connection to read its first request.
+func (u *upgrader) waitFresh(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for {
+		u.mu.Lock()
+		n := len(u.fresh)
+		u.mu.Unlock()
+		if n == 0 || time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// handoffListener 在升级后停止接受连接，但不会让Serve返回错误。 handoffListener stops accepting once the upgrade handed the socket
+// over to the child, without making Serve fail: Accept blocks until the server closes the listener on shutdown.
+type handoffListener struct {
+	net.Listener
+	once    sync.Once
+	closed  chan struct{}
+	handoff int32
+}
+
+func (l *handoffListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil && atomic.LoadInt32(&l.handoff) == 1 {
+		<-l.closed
+		return nil, net.ErrClosed
+	}
+	return conn, err
+}
+
+// handOff 关闭父进程的文件描述符，子进程继续在同一个套接字上接受连接。 handOff closes the parent's descriptor,
+// the child keeps accepting on the same socket.
+func (l *handoffListener) handOff() {
+	atomic.StoreInt32(&l.handoff, 1)
+	l.Listener.Close()
+}
+
+func (l *handoffListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	if atomic.LoadInt32(&l.handoff) == 1 {
+		return nil
+	}
+	return l.Listener.Close()
+}
+
+// forkChild 启动继承监听器的子进程，并等待它就绪 forkChild starts a child inheriting the listeners and waits for it to be ready.
+func forkChild(listeners []net.Listener) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	files := make([]*os.File, 0, len(listeners)+1)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, listener := range listeners {
+		filer, ok := listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("监听器不支持文件描述符 listener %s does not support file descriptors", listener.Addr())
+		}
+		f, err := filer.File()
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+	files = append(files, readyW)
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), upgradeFdsEnv+"="+strconv.Itoa(len(listeners)))
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	DebugPrint("已启动子进程 Started child process %d", cmd.Process.Pid)
+	readyW.Close()
+	files = files[:len(files)-1]
+
+	//子进程在就绪时写入一个字节，退出时关闭管道 // the child writes a byte once ready, the pipe is closed if it exits
+	result := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		if n, _ := readyR.Read(b); n == 1 {
+			result <- nil
+			return
+		}
+		result <- errors.New("子进程在就绪前退出 child exited before being ready")
+	}()
+	go cmd.Wait()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(UpgradeTimeout):
+		cmd.Process.Kill()
+		return errors.New("等待子进程就绪超时 timed out waiting for the child to be ready")
+	}
+}
+
+// inheritedListeners 返回从父进程继承的监听器和就绪管道 inheritedListeners returns the listeners and the ready pipe inherited from the parent.
+// 如果进程不是由Upgrade启动的，则两者都为nil。 Both are nil if the process was not started by Upgrade.
+func inheritedListeners() ([]net.Listener, *os.File, error) {
+	value := os.Getenv(upgradeFdsEnv)
+	if value == "" {
+		return nil, nil, nil
+	}
+	os.Unsetenv(upgradeFdsEnv)
+	count, err := strconv.Atoi(value)
+	if err != nil || count <= 0 {
+		return nil, nil, fmt.Errorf("无效的 invalid %s=%q", upgradeFdsEnv, value)
+	}
+
+	listeners := make([]net.Listener, 0, count)
+	for fd := 3; fd < 3+count; fd++ {
+		f := os.NewFile(uintptr(fd), fmt.Sprintf("fd@%d", fd))
+		listener, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			closeListeners(listeners)
+			return nil, nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+	ready := os.NewFile(uintptr(3+count), "ready")
+	return listeners, ready, nil
+}
+
+func closeListeners(listeners []net.Listener) {
+	for _, listener := range listeners {
+		listener.Close()
+	}
+}
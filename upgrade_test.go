@@ -0,0 +1,145 @@
+//go:build !windows
+
+package gin_web
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// upgradeHelperEnv 让测试二进制文件作为可升级服务器运行 upgradeHelperEnv makes the test binary run as an upgradable server.
+const upgradeHelperEnv = "GIN_WEB_TEST_UPGRADE_ADDR"
+
+// TestUpgradeHelperProcess 不是真正的测试，它是TestRunUpgradable启动的服务器进程。 TestUpgradeHelperProcess isn't a real test,
+// it is the server process started by TestRunUpgradable. Upgrade re-executes it with the same arguments.
+func TestUpgradeHelperProcess(t *testing.T) {
+	addr := os.Getenv(upgradeHelperEnv)
+	if addr == "" {
+		t.Skip("helper process for TestRunUpgradable")
+	}
+	router := New()
+	router.GET("/pid", func(c *Context) {
+		c.String(http.StatusOK, strconv.Itoa(os.Getpid()))
+	})
+	if err := router.RunUpgradable(addr); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestRunUpgradable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts two server processes")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client := &http.Client{
+		Timeout:   2 * time.Second,
+		Transport: &http.Transport{DisableKeepAlives: true},
+	}
+	getPid := func() (int, error) {
+		resp, err := client.Get("http://" + addr + "/pid")
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(string(body))
+	}
+	waitPid := func(accept func(pid int) bool) int {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			if pid, err := getPid(); err == nil && accept(pid) {
+				return pid
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatal("timed out waiting for the server")
+		return 0
+	}
+
+	parent := exec.Command(os.Args[0], "-test.run=^TestUpgradeHelperProcess$")
+	parent.Env = append(os.Environ(), upgradeHelperEnv+"="+addr)
+	if err := parent.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Process.Kill()
+	exited := make(chan error, 1)
+	go func() { exited <- parent.Wait() }()
+
+	if pid := waitPid(func(int) bool { return true }); pid != parent.Process.Pid {
+		t.Fatalf("served by pid %d, want the parent %d", pid, parent.Process.Pid)
+	}
+
+	//升级期间持续发送请求，任何一个都不应失败 // keep sending requests during the upgrade, none of them may fail
+	var failed int32
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if _, err := getPid(); err != nil {
+				atomic.AddInt32(&failed, 1)
+				t.Logf("request failed during upgrade: %v", err)
+			}
+		}
+	}()
+
+	if err := parent.Process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-exited:
+		if err != nil {
+			t.Errorf("parent exited with %v, want a clean exit after the upgrade", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("parent did not exit after the upgrade")
+	}
+
+	child := waitPid(func(pid int) bool { return pid != parent.Process.Pid })
+	defer syscall.Kill(child, syscall.SIGKILL)
+	close(stop)
+	wg.Wait()
+	if n := atomic.LoadInt32(&failed); n > 0 {
+		t.Errorf("%d request(s) failed during the upgrade", n)
+	}
+
+	if err := syscall.Kill(child, syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := getPid(); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("child kept serving after SIGTERM")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
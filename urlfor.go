@@ -0,0 +1,169 @@
+package gin_web
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// urlForFuncName 是在 Engine.FuncMap 中注册 Engine.URLFor 的名称 urlForFuncName is the name Engine.URLFor is registered under in Engine.FuncMap.
+const urlForFuncName = "urlFor"
+
+// namedRoute 记录命名路由的方法和完整路径模式 namedRoute records the method and the full path pattern of a named route.
+// 主机和路径模式在注册时解析一次，URLFor不会重新编译约束。 The host and path patterns are parsed once at registration so URLFor doesn't recompile constraints.
+type namedRoute struct {
+	host      string
+	method    string
+	path      string
+	hostParts []urlSegment
+	pathParts []urlSegment
+}
+
+// urlSegment 是路由模式的一部分：字面文本或参数。 urlSegment is a part of a route pattern: literal text or a param.
+type urlSegment struct {
+	text       string
+	param      bool
+	catchAll   bool
+	constraint *paramConstraint
+}
+
+// parsePathSegments 将路径模式拆分为字面文本和参数 parsePathSegments splits a path pattern into literal text and params.
+func parsePathSegments(path string) []urlSegment {
+	var segments []urlSegment
+	for pattern := path; ; {
+		wildcard, i, _ := findWildcard(pattern)
+		if i < 0 {
+			return append(segments, urlSegment{text: pattern})
+		}
+		segments = append(segments, urlSegment{text: pattern[:i]})
+		pattern = pattern[i+len(wildcard):]
+		if wildcard[0] == '*' {
+			segments = append(segments, urlSegment{text: wildcard[1:], param: true, catchAll: true})
+			continue
+		}
+		key, constraint := parseParamWildcard(wildcard, path)
+		segments = append(segments, urlSegment{text: key, param: true, constraint: constraint})
+	}
+}
+
+// parseHostSegments 将诸如 :tenant.example.com 的主机模式拆分为标签 parseHostSegments splits a host pattern such as :tenant.example.com into its labels.
+func parseHostSegments(host string) []urlSegment {
+	var segments []urlSegment
+	for i, label := range strings.Split(host, ".") {
+		if i > 0 {
+			segments = append(segments, urlSegment{text: "."})
+		}
+		if label[0] == ':' {
+			segments = append(segments, urlSegment{text: label[1:], param: true})
+		} else {
+			segments = append(segments, urlSegment{text: label})
+		}
+	}
+	return segments
+}
+
+// addRouteName 以给定名称注册路由，名称重复时会panic。 addRouteName registers a route under the given name, it panics if the name is already taken.
+func (t *routeTable) addRouteName(name, host, method, path string) {
+	if r, ok := t.names[name]; ok {
+		panic("路由名称已被注册 route name '" + name + "' is already registered for " + r.method + " " + r.host + r.path)
+	}
+	if t.names == nil {
+		t.names = make(map[string]namedRoute)
+	}
+	r := namedRoute{host: host, method: method, path: path, pathParts: parsePathSegments(path)}
+	if host != "" {
+		r.hostParts = parseHostSegments(host)
+	}
+	t.names[name] = r
+}
+
+// routeName 返回给定主机、方法和路径的路由名称，如果没有命名则返回空字符串。 routeName returns the name of the route with the given host, method and path, or an empty string if it is not named.
+func (t *routeTable) routeName(host, method, path string) string {
+	for name, r := range t.names {
+		if r.host == host && r.method == method && r.path == path {
+			return name
+		}
+	}
+	return ""
+}
+
+// URLFor 根据命名路由的注册模式生成转义后的路径。 URLFor builds the escaped path of a named route from its registered pattern.
+// 参数以键值对的形式给出，值使用fmt.Sprint格式化。 Params are given as key/value pairs, values are formatted with fmt.Sprint.
+// 缺少参数、多余参数或值不满足约束时返回错误。 An error is returned for missing or extra params, or for a value failing its constraint.
+//     router.HandleNamed("user", "GET", "/users/:id<int>", handler)
+//     path, err := router.URLFor("user", "id", 42) // "/users/42"
+// 在主机下注册的路由生成网络路径引用，主机参数与路径参数一样填充。 A route registered under a Host builds a network-path reference,
+// host params are filled like path params and must be a single non-empty label:
+//     router.Host(":tenant.example.com").HandleNamed("home", "GET", "/", handler)
+//     url, err := router.URLFor("home", "tenant", "acme") // "//acme.example.com/"
+// 它也以 urlFor 注册在 Engine.FuncMap 中，供HTML模板使用。 It is also registered as urlFor in Engine.FuncMap for HTML templates:
+//     <a href="{{ urlFor "user" "id" .ID }}">
+func (engine *Engine) URLFor(name string, params ...interface{}) (string, error) {
+	r, ok := engine.routes().names[name]
+	if !ok {
+		return "", fmt.Errorf("gin-web: unknown route name %q", name)
+	}
+	if len(params)%2 != 0 {
+		return "", errors.New("gin-web: URLFor params must be key/value pairs")
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("gin-web: URLFor param key %v must be a string", params[i])
+		}
+		values[key] = fmt.Sprint(params[i+1])
+	}
+
+	var buf strings.Builder
+	if r.hostParts != nil {
+		buf.WriteString("//")
+		for _, segment := range r.hostParts {
+			if !segment.param {
+				buf.WriteString(segment.text)
+				continue
+			}
+			value, ok := values[segment.text]
+			if !ok {
+				return "", fmt.Errorf("gin-web: missing host param %q for route %q", segment.text, name)
+			}
+			delete(values, segment.text)
+			if value == "" || strings.ContainsAny(value, "./:@[]") || url.PathEscape(value) != value {
+				return "", fmt.Errorf("gin-web: host param %q=%q is not a valid host label for route %q", segment.text, value, name)
+			}
+			buf.WriteString(value)
+		}
+	}
+	for _, segment := range r.pathParts {
+		if !segment.param {
+			buf.WriteString(segment.text)
+			continue
+		}
+		value, ok := values[segment.text]
+		if !ok {
+			return "", fmt.Errorf("gin-web: missing param %q for route %q", segment.text, name)
+		}
+		delete(values, segment.text)
+
+		if segment.catchAll {
+			//全匹配参数的值以'/'开头 // the value of a catch-all param starts with '/'
+			value = strings.TrimPrefix(value, "/")
+			parts := strings.Split(value, "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			buf.WriteString(strings.Join(parts, "/"))
+			continue
+		}
+		if segment.constraint != nil && !segment.constraint.match(value) {
+			return "", fmt.Errorf("gin-web: param %q=%q does not satisfy <%s> for route %q", segment.text, value, segment.constraint.expr, name)
+		}
+		buf.WriteString(url.PathEscape(value))
+	}
+
+	for key := range values {
+		return "", fmt.Errorf("gin-web: unexpected param %q for route %q", key, name)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,64 @@
+package gin_web
+
+import (
+	"html/template"
+	"testing"
+)
+
+func TestURLFor(t *testing.T) {
+	router := New()
+	router.HandleNamed("user", "GET", "/users/:id<int>", func(*Context) {})
+	router.HandleNamed("file", "GET", "/files/*path", func(*Context) {})
+	router.Host(":tenant.example.com").HandleNamed("tenant", "GET", "/projects/:id<int>", func(*Context) {})
+	router.Host("static.example.com").HandleNamed("static", "GET", "/", func(*Context) {})
+
+	tests := []struct {
+		name    string
+		route   string
+		params  []interface{}
+		want    string
+		wantErr bool
+	}{
+		{"param", "user", []interface{}{"id", 42}, "/users/42", false},
+		{"catch-all", "file", []interface{}{"path", "/a b/c"}, "/files/a%20b/c", false},
+		{"unknown route", "nope", nil, "", true},
+		{"missing param", "user", nil, "", true},
+		{"extra param", "user", []interface{}{"id", 1, "x", 2}, "", true},
+		{"odd params", "user", []interface{}{"id"}, "", true},
+		{"constraint", "user", []interface{}{"id", "abc"}, "", true},
+		{"host param", "tenant", []interface{}{"tenant", "acme", "id", 7}, "//acme.example.com/projects/7", false},
+		{"missing host param", "tenant", []interface{}{"id", 7}, "", true},
+		{"empty host param", "tenant", []interface{}{"tenant", "", "id", 7}, "", true},
+		{"host param with a dot", "tenant", []interface{}{"tenant", "a.b", "id", 7}, "", true},
+		{"host param with a slash", "tenant", []interface{}{"tenant", "a/b", "id", 7}, "", true},
+		{"host constraint still applies", "tenant", []interface{}{"tenant", "acme", "id", "x"}, "", true},
+		{"exact host", "static", nil, "//static.example.com/", false},
+	}
+	for _, tt := range tests {
+		got, err := router.URLFor(tt.route, tt.params...)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: URLFor() = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSetFuncMapCopies(t *testing.T) {
+	router := New()
+	funcMap := template.FuncMap{"upper": func(s string) string { return s }}
+	router.SetFuncMap(funcMap)
+
+	if _, ok := funcMap[urlForFuncName]; ok {
+		t.Error("SetFuncMap modified the caller's map")
+	}
+	if _, ok := router.FuncMap["upper"]; !ok {
+		t.Error("SetFuncMap dropped a caller func")
+	}
+	if _, ok := router.FuncMap[urlForFuncName]; !ok {
+		t.Error("SetFuncMap did not add urlFor")
+	}
+
+	router.SetFuncMap(nil)
+	if _, ok := router.FuncMap[urlForFuncName]; !ok {
+		t.Error("SetFuncMap(nil) did not add urlFor")
+	}
+}